@@ -3,7 +3,6 @@ package data
 import (
 	"errors"
 	"fmt"
-	"log"
 	"sync"
 
 	"github.com/loanpal-engineering/exttra/pkg"
@@ -26,6 +25,8 @@ type (
 		index    map[interface{}][]uint64
 		children map[uint64]pkg.Composer
 		nm       []map[uint64]bool
+		// rows excluded from every version, only set on the root node. See [Exclude]
+		excluded map[uint32]bool
 	}
 
 	Opt func(*node) (*node, error)
@@ -58,9 +59,8 @@ func NewNode(id *uint64, opts ...Opt) (pkg.Composer, error) {
 	i.nm[0] = make(map[uint64]bool)
 	i.children = make(map[uint64]pkg.Composer)
 	for _, op := range opts {
-		i, err = op(i)
-		if err != nil {
-			log.Fatalf("data/tree: option error %s", err.Error())
+		if i, err = op(i); err != nil {
+			return nil, errors.New(fmt.Sprintf("data/tree: option error %s", err.Error()))
 		}
 	}
 	return i, nil
@@ -342,7 +342,16 @@ func (i *node) Excluded(id uint64) (bool, error) {
 	}
 }
 
-// Merge all nilmaps into one
+// Exclude row [row] from every version of the tree
+func (i *node) Exclude(row uint32) {
+	n := root(i)
+	if n.excluded == nil {
+		n.excluded = make(map[uint32]bool)
+	}
+	n.excluded[row] = true
+}
+
+// Merge all nilmaps, and the excluded rows, into one
 func (i *node) Excludes() []bool {
 	var (
 		root            = i
@@ -373,6 +382,11 @@ func (i *node) Excludes() []bool {
 			}
 		}
 	}
+	for row := range root.excluded {
+		if int(row) < len(excludes) {
+			excludes[row] = true
+		}
+	}
 	return excludes
 }
 func (i *node) Nullable() pkg.Nullable {
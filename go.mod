@@ -1,10 +1,11 @@
 module github.com/loanpal-engineering/exttra
 
-go 1.12
+go 1.17
 
 require (
 	github.com/araddon/dateparse v0.0.0-20190622164848-0fb0a474d195
 	github.com/nleeper/goment v0.0.0-20190304152151-62477c661bec
 	github.com/pkg/errors v0.8.1
 	github.com/stretchr/testify v1.3.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0
 )
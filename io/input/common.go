@@ -2,19 +2,159 @@ package input
 
 import (
 	"encoding/csv"
+	"errors"
+	"fmt"
 	"io"
 
 	"github.com/loanpal-engineering/exttra/types"
 )
 
 type (
+	// Input is the row reader consumed by the parser.
+	// Every source format implements Input, new formats can be plugged in by implementing
+	// Input directly or by wrapping a [Reader] with [Records].
 	Input interface {
+		// Get the schema of this input
 		GetSchema() types.Signature
-		GetReader() interface{}
+		// Get the header row found at [index].
+		// Records preceding the header are discarded.
+		Header(index uint32) ([]string, error)
+		// Get the next record.
+		// io.EOF is returned once the input is exhausted.
+		Next() ([]string, error)
+		// Get the source line number of the last record returned by Header or Next.
+		Line() int
 	}
-	flatFile struct {
-		source     io.Reader
+	// Reader is the record level contract used to build an Input with [Records].
+	// A *csv.Reader satisfies Reader.
+	Reader interface {
+		Read() ([]string, error)
+	}
+	// A Reader may report the source line of the last record read,
+	// otherwise the line is the count of records read
+	liner interface {
+		Line() int
+	}
+	records struct {
+		reader     Reader
 		definition *types.Schema
-		reader     *csv.Reader
+		line       int
+	}
+	// failed is returned by a constructor that can not create its input,
+	// the error is returned by Header and Next
+	failed struct {
+		definition types.Signature
+		err        error
 	}
+	flatFile struct {
+		source io.Reader
+		reader *csv.Reader
+		trim   Trim
+	}
+	// Trim policy applied to each field of a flat file
+	Trim int
+
+	Opt func(*flatFile) (*flatFile, error)
 )
+
+const (
+	TrimNone Trim = iota
+	TrimLeading
+	TrimTrailing
+	TrimBoth
+)
+
+// Variable length records, see [FieldsPerRecord]
+const VariableFields = -1
+
+// Create a new input object from any record [Reader] and schema.
+// If the reader has a `Line() int` method it is used as the source line of each record.
+// If the reader implements [Native] the typed values of each record are available to the parser.
+func Records(reader Reader, def types.Signature) Input {
+	original, err := schema("input.Records", def)
+	if err != nil {
+		return fail(def, err)
+	}
+	r := &records{
+		reader:     reader,
+		definition: original,
+	}
+	if n, ok := reader.(Native); ok {
+		return &typed{records: r, native: n}
+	}
+	return r
+}
+
+func schema(caller string, def types.Signature) (*types.Schema, error) {
+	if def == nil {
+		return nil, errors.New(fmt.Sprintf("%s schema is required", caller))
+	}
+	original, ok := def.(*types.Schema)
+	if !ok {
+		// Signature is an empty interface, used only to mask the full schema object
+		return nil, errors.New(fmt.Sprintf("%s schema bad cast", caller))
+	}
+	return original, original.Err()
+}
+
+// Create an input failing with [err], for a constructor that can not create its input
+func fail(def types.Signature, err error) Input {
+	return &failed{definition: def, err: err}
+}
+
+// Get the schema of this input
+func (i *failed) GetSchema() types.Signature {
+	return i.definition
+}
+
+// Get the error of the constructor
+func (i *failed) Header(uint32) ([]string, error) {
+	return nil, i.err
+}
+
+// Get the error of the constructor
+func (i *failed) Next() ([]string, error) {
+	return nil, i.err
+}
+
+func (i *failed) Line() int {
+	return 0
+}
+
+// Get the schema of this input
+func (i *records) GetSchema() types.Signature {
+	return i.definition
+}
+
+// Get the header row found at [index]
+func (i *records) Header(index uint32) ([]string, error) {
+	for {
+		row, err := i.Next()
+		if err != nil {
+			return nil, err
+		}
+		if index == 0 {
+			return row, nil
+		}
+		index--
+	}
+}
+
+// Get the next record
+func (i *records) Next() ([]string, error) {
+	row, err := i.reader.Read()
+	if row == nil && err != nil {
+		return nil, err
+	}
+	if l, ok := i.reader.(liner); ok {
+		i.line = l.Line()
+	} else {
+		i.line++
+	}
+	return row, err
+}
+
+// Get the source line number of the last record
+func (i *records) Line() int {
+	return i.line
+}
@@ -0,0 +1,148 @@
+package input
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+// Factory creates the input of a single (decompressed) source, ex.
+//
+//	func(r io.Reader, def types.Signature) input.Input { return input.Tsv(r, def) }
+type Factory func(io.Reader, types.Signature) Input
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	zipMagic   = []byte("PK\x03\x04")
+)
+
+// Sniff the magic bytes of [source] and return the decompressed stream(s).
+// Gzip and bzip2 sources return a single stream named by the gzip header (if any), uncompressed sources are returned as is.
+// Zip archives return a stream for each member matching one of [members] (see path.Match),
+// or every file in the archive if no members are provided, in archive order.
+func Decompress(source io.Reader, members ...string) ([]Source, error) {
+	buffered := bufio.NewReader(source)
+	magic, err := buffered.Peek(4)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		return []Source{{Name: gz.Header.Name, Reader: gz}}, nil
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return []Source{{Reader: bzip2.NewReader(buffered)}}, nil
+	case bytes.HasPrefix(magic, zipMagic):
+		return unzip(source, buffered, members)
+	default:
+		return []Source{{Reader: buffered}}, nil
+	}
+}
+
+func unzip(source io.Reader, buffered *bufio.Reader, members []string) ([]Source, error) {
+	var (
+		archive *zip.Reader
+		err     error
+	)
+	// files can be read in place, any other source is read to memory
+	if f, ok := source.(*os.File); ok {
+		var info os.FileInfo
+		if info, err = f.Stat(); err != nil {
+			return nil, err
+		}
+		archive, err = zip.NewReader(f, info.Size())
+	} else {
+		var b []byte
+		if b, err = ioutil.ReadAll(buffered); err != nil {
+			return nil, err
+		}
+		archive, err = zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Source, 0, len(archive.File))
+	for _, f := range archive.File {
+		if strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		selected := len(members) == 0
+		for _, m := range members {
+			if ok, err := path.Match(m, f.Name); err != nil {
+				return nil, err
+			} else if ok {
+				selected = true
+				break
+			}
+		}
+		if !selected {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			for _, o := range out {
+				o.Reader.(*member).Close()
+			}
+			return nil, err
+		}
+		out = append(out, Source{Name: f.Name, Reader: &member{rc: rc}})
+	}
+	if len(out) == 0 {
+		return nil, errors.New(fmt.Sprintf("input/compressed: no archive member matches %v", members))
+	}
+	return out, nil
+}
+
+// A zip archive member, closed once it has been read to the end
+type member struct {
+	rc     io.ReadCloser
+	closed bool
+}
+
+func (m *member) Read(p []byte) (int, error) {
+	if m.closed {
+		return 0, io.EOF
+	}
+	n, err := m.rc.Read(p)
+	if err == io.EOF {
+		m.Close()
+	}
+	return n, err
+}
+
+func (m *member) Close() error {
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	return m.rc.Close()
+}
+
+// Create a new input object from a compressed source.
+// The source is decompressed with [Decompress] and each stream is read with the input created by [factory].
+// When an archive holds several members they are read as one logical input, see [Concat].
+func Compressed(source io.Reader, def types.Signature, factory Factory, members ...string) Input {
+	streams, err := Decompress(source, members...)
+	if err != nil {
+		return fail(def, err)
+	}
+	if len(streams) == 1 {
+		return factory(streams[0].Reader, def)
+	}
+	return Concat(def, factory, streams...)
+}
@@ -0,0 +1,179 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+type (
+	// Source is a named reader, the name is reported as the provenance of each record read from the reader.
+	Source struct {
+		Name   string
+		Reader io.Reader
+	}
+	// Provenance is implemented by inputs reading several named sources.
+	// Together with [Input.Line] it locates the last record in the original source.
+	Provenance interface {
+		Source() string
+	}
+	// chain reads several inputs sharing a schema as one logical input.
+	chain struct {
+		definition *types.Schema
+		inputs     []Input
+		names      []string
+		current    int
+		index      uint32
+		header     bool
+		// canonical column names of the first input's header
+		layout []string
+		// position of each layout column in the current input, -1 when the column is missing
+		positions []int
+		record    []string
+	}
+)
+
+// Create a new input object reading several sources sharing one schema as a single dataset.
+// Each source is read with the input created by [factory].
+// The header row is taken from the first source. The header of every following source is validated
+// against the first, columns are matched by name or alias and reordered to the first source's layout,
+// repeated header rows are discarded.
+// The input implements [Provenance], the name of the source is available for each record.
+func Concat(def types.Signature, factory Factory, sources ...Source) Input {
+	original, err := schema("input.Concat", def)
+	if err != nil {
+		return fail(def, err)
+	}
+	if len(sources) == 0 {
+		return fail(def, errors.New("input.Concat one or more sources are required"))
+	}
+	i := new(chain)
+	i.definition = original
+	for _, s := range sources {
+		i.inputs = append(i.inputs, factory(s.Reader, def))
+		i.names = append(i.names, s.Name)
+	}
+	return i
+}
+
+// Get the schema of this input
+func (i *chain) GetSchema() types.Signature {
+	return i.definition
+}
+
+// Get the name of the source of the last record
+func (i *chain) Source() string {
+	return i.names[i.current]
+}
+
+// Get the header row of the first source
+func (i *chain) Header(index uint32) ([]string, error) {
+	i.header = true
+	i.index = index
+	row, err := i.inputs[i.current].Header(index)
+	if err != nil {
+		return row, err
+	}
+	i.layout = make([]string, len(row))
+	for ii, field := range row {
+		i.layout[ii] = i.canonical(field)
+	}
+	i.record = make([]string, len(row))
+	return row, nil
+}
+
+// Get the next record, moving to the next source once the current source is exhausted
+func (i *chain) Next() ([]string, error) {
+	for {
+		row, err := i.inputs[i.current].Next()
+		if err == io.EOF && i.current < len(i.inputs)-1 {
+			i.current++
+			if i.header {
+				if err = i.align(); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if row == nil || i.positions == nil {
+			return row, err
+		}
+		for ii, p := range i.positions {
+			if p < 0 || p >= len(row) {
+				i.record[ii] = ""
+			} else {
+				i.record[ii] = row[p]
+			}
+		}
+		return i.record, err
+	}
+}
+
+// Get the source line number of the last record in the current source
+func (i *chain) Line() int {
+	return i.inputs[i.current].Line()
+}
+
+// Read the header of the current source and map its columns to the layout of the first source
+func (i *chain) align() error {
+	row, err := i.inputs[i.current].Header(i.index)
+	if err == io.EOF {
+		// an empty source
+		i.positions = nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+	found := make(map[string]int)
+	for ii, field := range row {
+		name := i.canonical(field)
+		if _, exists := found[name]; !exists {
+			found[name] = ii
+		}
+	}
+	positions := make([]int, len(i.layout))
+	identity := len(row) == len(i.layout)
+	missing := make([]string, 0)
+	for ii, name := range i.layout {
+		p, ok := found[name]
+		if !ok {
+			p = -1
+			for _, c := range i.definition.Cols() {
+				if c.Name == name && c.Required {
+					missing = append(missing, name)
+				}
+			}
+		}
+		positions[ii] = p
+		identity = identity && p == ii
+	}
+	if len(missing) > 0 {
+		return errors.New(fmt.Sprintf("input/concat: %s is missing column(s) %s",
+			i.names[i.current], strings.Join(missing, ",")))
+	}
+	if identity {
+		i.positions = nil
+	} else {
+		i.positions = positions
+	}
+	return nil
+}
+
+// Resolve a header field to the schema column name, fields not in the schema are returned as is
+func (i *chain) canonical(field string) string {
+	field = strings.TrimSpace(field)
+	for _, c := range i.definition.Cols() {
+		if field == c.Name {
+			return c.Name
+		}
+		for _, a := range c.Aliases {
+			if field == a {
+				return c.Name
+			}
+		}
+	}
+	return field
+}
@@ -0,0 +1,273 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+)
+
+type (
+	// Character encoding of a source
+	Encoding int
+
+	// decoder transcodes a source to UTF-8
+	decoder struct {
+		source   *bufio.Reader
+		enc      Encoding
+		detected bool
+		chunk    []byte
+		work     []byte
+		pending  []byte // undecoded bytes carried over from the last chunk
+		out      bytes.Buffer
+		line     int
+		eof      bool
+	}
+)
+
+const (
+	// Detect the encoding from the byte order mark, or from a sample of the source when there is none
+	Auto Encoding = iota
+	UTF8
+	UTF16LE
+	UTF16BE
+	Latin1
+	Windows1252
+)
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+	// windows-1252 code points for 0x80 - 0x9f, zero where the byte is undefined
+	windows1252 = [32]rune{
+		0x20ac, 0, 0x201a, 0x0192, 0x201e, 0x2026, 0x2020, 0x2021, 0x02c6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017d, 0,
+		0, 0x2018, 0x2019, 0x201c, 0x201d, 0x2022, 0x2013, 0x2014, 0x02dc, 0x2122, 0x0161, 0x203a, 0x0153, 0, 0x017e, 0x0178,
+	}
+)
+
+func (e Encoding) String() string {
+	return [...]string{
+		"Auto",
+		"UTF8",
+		"UTF16LE",
+		"UTF16BE",
+		"Latin1",
+		"Windows1252",
+	}[e]
+}
+
+// Create a reader transcoding [source] from [enc] to UTF-8.
+// A byte order mark is always removed. Invalid byte sequences are replaced with U+FFFD
+// and logged as defects with the line they were found on.
+func Decode(source io.Reader, enc Encoding) io.Reader {
+	return &decoder{
+		source: bufio.NewReader(source),
+		enc:    enc,
+		chunk:  make([]byte, 4096),
+		line:   1,
+	}
+}
+
+// Set the character encoding of the source, the default is [Auto].
+func Charset(enc Encoding) Opt {
+	return func(i *flatFile) (*flatFile, error) {
+		if d, ok := i.source.(*decoder); ok {
+			d.enc = enc
+		}
+		return i, nil
+	}
+}
+
+// Transcode [source] with the encoding set by [opts], for inputs read without a csv reader
+func decoded(source io.Reader, opts []Opt) (io.Reader, error) {
+	i := &flatFile{source: Decode(source, Auto)}
+	for _, o := range opts {
+		if _, err := o(i); err != nil {
+			return nil, err
+		}
+	}
+	return i.source, nil
+}
+
+func (d *decoder) Read(p []byte) (int, error) {
+	if !d.detected {
+		d.detected = true
+		if err := d.detect(); err != nil {
+			return 0, err
+		}
+	}
+	for d.out.Len() == 0 && !d.eof {
+		n, err := d.source.Read(d.chunk)
+		if err == io.EOF {
+			d.eof = true
+		} else if err != nil {
+			return 0, err
+		}
+		d.work = append(append(d.work[:0], d.pending...), d.chunk[:n]...)
+		d.decode(d.work)
+	}
+	if d.out.Len() == 0 {
+		return 0, io.EOF
+	}
+	return d.out.Read(p)
+}
+
+// Remove the byte order mark, when the encoding is [Auto] the encoding is taken from the byte order mark
+// or a sample of the source
+func (d *decoder) detect() error {
+	sample, err := d.source.Peek(len(d.chunk))
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return err
+	}
+	switch {
+	case bytes.HasPrefix(sample, bomUTF8) && (d.enc == Auto || d.enc == UTF8):
+		d.enc = UTF8
+		_, _ = d.source.Discard(len(bomUTF8))
+	case bytes.HasPrefix(sample, bomUTF16LE) && (d.enc == Auto || d.enc == UTF16LE):
+		d.enc = UTF16LE
+		_, _ = d.source.Discard(len(bomUTF16LE))
+	case bytes.HasPrefix(sample, bomUTF16BE) && (d.enc == Auto || d.enc == UTF16BE):
+		d.enc = UTF16BE
+		_, _ = d.source.Discard(len(bomUTF16BE))
+	case d.enc == Auto:
+		d.enc = guess(sample)
+	}
+	return nil
+}
+
+// Guess the encoding of a sample without a byte order mark
+func guess(sample []byte) Encoding {
+	var even, odd int
+	for ii, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if ii%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+	// ascii text encoded as UTF-16 has a zero in every other byte
+	if half := len(sample) / 4; half > 0 {
+		if odd > half && even == 0 {
+			return UTF16LE
+		} else if even > half && odd == 0 {
+			return UTF16BE
+		}
+	}
+	// ignore a rune cut at the end of the sample
+	for cut := 0; cut < utf8.UTFMax && len(sample) > 0; cut++ {
+		if utf8.Valid(sample) {
+			return UTF8
+		}
+		sample = sample[:len(sample)-1]
+	}
+	return Windows1252
+}
+
+// Decode [b] to the output buffer, bytes of an incomplete sequence are kept until the next chunk
+func (d *decoder) decode(b []byte) {
+	d.pending = d.pending[:0]
+	switch d.enc {
+	case UTF16LE, UTF16BE:
+		for len(b) > 0 {
+			if len(b) < 2 {
+				if !d.eof {
+					d.pending = append(d.pending, b...)
+				} else {
+					d.invalid(b)
+				}
+				return
+			}
+			r := d.unit(b)
+			if utf16.IsSurrogate(r) {
+				if len(b) < 4 && !d.eof {
+					d.pending = append(d.pending, b...)
+					return
+				}
+				if len(b) >= 4 {
+					if pair := utf16.DecodeRune(r, d.unit(b[2:])); pair != utf8.RuneError {
+						d.write(pair)
+						b = b[4:]
+						continue
+					}
+				}
+				d.invalid(b[:2])
+				b = b[2:]
+				continue
+			}
+			d.write(r)
+			b = b[2:]
+		}
+	case Latin1:
+		for _, c := range b {
+			d.write(rune(c))
+		}
+	case Windows1252:
+		for ii, c := range b {
+			if c < 0x80 || c > 0x9f {
+				d.write(rune(c))
+			} else if r := windows1252[c-0x80]; r != 0 {
+				d.write(r)
+			} else {
+				d.invalid(b[ii : ii+1])
+			}
+		}
+	default:
+		if utf8.Valid(b) {
+			d.count(b)
+			d.out.Write(b)
+			return
+		}
+		for len(b) > 0 {
+			r, size := utf8.DecodeRune(b)
+			if r == utf8.RuneError && size <= 1 {
+				if !utf8.FullRune(b) && !d.eof {
+					d.pending = append(d.pending, b...)
+					return
+				}
+				d.invalid(b[:1])
+				b = b[1:]
+				continue
+			}
+			d.count(b[:size])
+			d.out.Write(b[:size])
+			b = b[size:]
+		}
+	}
+}
+
+func (d *decoder) unit(b []byte) rune {
+	if d.enc == UTF16BE {
+		return rune(b[0])<<8 | rune(b[1])
+	}
+	return rune(b[1])<<8 | rune(b[0])
+}
+
+func (d *decoder) write(r rune) {
+	if r == '\n' {
+		d.line++
+	}
+	d.out.WriteRune(r)
+}
+
+func (d *decoder) count(b []byte) {
+	d.line += bytes.Count(b, []byte{'\n'})
+}
+
+// Replace an invalid sequence and log the defect
+func (d *decoder) invalid(b []byte) {
+	pkg.LogDefect(pkg.Defect{
+		Row:  -1,
+		Col:  -1,
+		Line: d.line,
+		Msg:  fmt.Sprintf("input/encoding: invalid %s sequence % x", d.enc.String(), b),
+	})
+	d.out.WriteRune(utf8.RuneError)
+}
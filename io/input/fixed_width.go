@@ -0,0 +1,89 @@
+package input
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+type fixedWidth struct {
+	source  *bufio.Reader
+	columns []*types.ColumnDefinition
+	header  bool
+	line    int
+	record  []string
+}
+
+// Read the next record.
+// Fixed width files do not carry a header, the first record read is built
+// from the schema column names so the parser can validate the layout as it would a csv header.
+// Blank lines are skipped.
+func (i *fixedWidth) Read() ([]string, error) {
+	if !i.header {
+		i.header = true
+		for ii, c := range i.columns {
+			i.record[ii] = c.Name
+		}
+		return i.record, nil
+	}
+	for {
+		line, err := i.source.ReadString('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, err
+		}
+		i.line++
+		line = strings.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			if err == io.EOF {
+				return nil, err
+			}
+			continue
+		}
+		runes := []rune(line)
+		for ii, c := range i.columns {
+			start, end := c.Offset, c.Offset+c.Width
+			if start > len(runes) {
+				start = len(runes)
+			}
+			if end > len(runes) {
+				end = len(runes)
+			}
+			i.record[ii] = strings.TrimSpace(string(runes[start:end]))
+		}
+		return i.record, nil
+	}
+}
+
+// Get the line of the last record read, the header built from the schema is line 0
+func (i *fixedWidth) Line() int {
+	return i.line
+}
+
+// Create a new fixed width input object.
+// Every column of the schema must be located with [types.Span].
+// Records are split by character offset and each field is trimmed of padding.
+// Optional properties:
+// 		Charset: character encoding of the source, detected by default
+func FixedWidth(source io.Reader, def types.Signature, opts ...Opt) Input {
+	original, err := schema("input.FixedWidth", def)
+	if err != nil {
+		return fail(def, err)
+	}
+	src, err := decoded(source, opts)
+	if err != nil {
+		return fail(def, err)
+	}
+	i := new(fixedWidth)
+	i.source = bufio.NewReader(src)
+	i.columns = original.Cols()
+	for _, c := range i.columns {
+		if c.Width <= 0 {
+			return fail(def, pkg.NewSchemaException(c.Name, "requires a span for input.FixedWidth"))
+		}
+	}
+	i.record = make([]string, len(i.columns))
+	return Records(i, original)
+}
@@ -2,39 +2,154 @@ package input
 
 import (
 	"encoding/csv"
+	"errors"
+	"fmt"
 	"io"
-	"log"
+	"strings"
+	"unicode"
 
 	"github.com/loanpal-engineering/exttra/types"
 )
 
-// Get the schema of this input
-func (i *flatFile) GetSchema() types.Signature {
-	return i.definition
+// Read the next record, applying the trim policy to each field.
+func (i *flatFile) Read() ([]string, error) {
+	row, err := i.reader.Read()
+	// with a whitespace delimiter the reader does not trim, a delimiter would be read as leading space
+	if (i.trim == TrimLeading || i.trim == TrimBoth) && !i.reader.TrimLeadingSpace {
+		for ii := range row {
+			row[ii] = strings.TrimLeftFunc(row[ii], unicode.IsSpace)
+		}
+	}
+	if i.trim == TrimTrailing || i.trim == TrimBoth {
+		for ii := range row {
+			row[ii] = strings.TrimRightFunc(row[ii], unicode.IsSpace)
+		}
+	}
+	return row, err
 }
 
-// Get a reader. Reader will provide a read and/or readAll method to consume the file
-func (i *flatFile) GetReader() interface{} {
-	return i.reader
+// Get the line of the last record read
+func (i *flatFile) Line() int {
+	line, _ := i.reader.FieldPos(0)
+	return line
 }
 
-// Create a new input object.
-func Csv(source io.Reader, def types.Signature) Input {
-	if def == nil {
-		log.Fatal("input.Csv schema is required")
+// Check option [name] is applied to delimited input, only [Charset] applies to fixed width and json lines input
+func delimited(i *flatFile, name string) error {
+	if i.reader == nil {
+		return errors.New(fmt.Sprintf("input/flat_file: %s only applies to delimited input", name))
+	}
+	return nil
+}
+
+// Set the field delimiter, the default is a comma.
+func Delimiter(r rune) Opt {
+	return func(i *flatFile) (*flatFile, error) {
+		if err := delimited(i, "Delimiter"); err != nil {
+			return nil, err
+		}
+		if r == 0 || r == '"' || r == '\r' || r == '\n' {
+			return nil, errors.New("input/flat_file: invalid delimiter")
+		}
+		i.reader.Comma = r
+		return i, nil
+	}
+}
+
+// Lines beginning with [r] are ignored.
+func Comment(r rune) Opt {
+	return func(i *flatFile) (*flatFile, error) {
+		if err := delimited(i, "Comment"); err != nil {
+			return nil, err
+		}
+		if r == i.reader.Comma || r == '"' || r == '\r' || r == '\n' {
+			return nil, errors.New("input/flat_file: invalid comment character")
+		}
+		i.reader.Comment = r
+		return i, nil
+	}
+}
+
+// Allow quotes to appear in unquoted fields, and non-doubled quotes in quoted fields.
+func LazyQuotes(b bool) Opt {
+	return func(i *flatFile) (*flatFile, error) {
+		if err := delimited(i, "LazyQuotes"); err != nil {
+			return nil, err
+		}
+		i.reader.LazyQuotes = b
+		return i, nil
+	}
+}
+
+// Set the number of fields expected per record.
+// If n is 0 (default) every record must have the same number of fields as the first record,
+// a positive n requires exactly n fields, and [VariableFields] disables the check.
+func FieldsPerRecord(n int) Opt {
+	return func(i *flatFile) (*flatFile, error) {
+		if err := delimited(i, "FieldsPerRecord"); err != nil {
+			return nil, err
+		}
+		if n < VariableFields {
+			return nil, errors.New("input/flat_file: fields per record must be >= -1")
+		}
+		i.reader.FieldsPerRecord = n
+		return i, nil
 	}
-	original, ok := def.(*types.Schema)
-	if !ok {
-		// todo: Signature is an empty interface, used only to mask the full schema object, this cast can
-		// break, how to handle casting error
-		log.Fatal("input.Csv schema bad cast")
+}
+
+// Set the trim policy applied to each field, the default is [TrimLeading] and [TrimNone] for [Tsv].
+// With a whitespace delimiter fields are trimmed once the record is split, an empty field is kept.
+func TrimSpace(t Trim) Opt {
+	return func(i *flatFile) (*flatFile, error) {
+		if err := delimited(i, "TrimSpace"); err != nil {
+			return nil, err
+		}
+		i.trim = t
+		i.reader.TrimLeadingSpace = t == TrimLeading || t == TrimBoth
+		return i, nil
+	}
+}
+
+// Create a new input object.
+// Optional properties:
+// 		Delimiter: field delimiter, defaults to ','
+// 		Comment: comment character, lines starting with this character are skipped
+// 		LazyQuotes: relax quoting rules
+// 		FieldsPerRecord: fields per record policy
+// 		TrimSpace: trim policy applied to each field
+// 		Charset: character encoding of the source, detected by default
+func Csv(source io.Reader, def types.Signature, opts ...Opt) Input {
+	original, err := schema("input.Csv", def)
+	if err != nil {
+		return fail(def, err)
 	}
 	i := new(flatFile)
-	i.source = source
-	i.definition = original
+	i.source = Decode(source, Auto)
 	i.reader = csv.NewReader(i.source)
 	i.reader.TrimLeadingSpace = true
 	i.reader.ReuseRecord = true
-	return i
+	i.trim = TrimLeading
+	for _, o := range opts {
+		ii, err := o(i)
+		if err != nil {
+			return fail(def, err)
+		}
+		i = ii
+	}
+	if unicode.IsSpace(i.reader.Comma) {
+		i.reader.TrimLeadingSpace = false
+	}
+	return Records(i, original)
 }
 
+// Create a new tab delimited input object, fields are not trimmed by default.
+// See [Csv] for optional properties.
+func Tsv(source io.Reader, def types.Signature, opts ...Opt) Input {
+	return Csv(source, def, append([]Opt{Delimiter('\t'), TrimSpace(TrimNone)}, opts...)...)
+}
+
+// Create a new pipe delimited input object.
+// See [Csv] for optional properties.
+func Psv(source io.Reader, def types.Signature, opts ...Opt) Input {
+	return Csv(source, def, append([]Opt{Delimiter('|')}, opts...)...)
+}
@@ -0,0 +1,46 @@
+package input
+
+import (
+	"errors"
+	"io"
+
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+// Infer a schema from the first [rows] records following the header row at [index], see [types.Infer].
+// The input is consumed by the sample, create a new input with the inferred schema to parse the file.
+// An input only needs a schema to be created, use an empty schema for the sample.
+//
+//	in := input.Csv(f, types.NewSchema())
+//	def, report, err := input.Infer(in, 0, 1000)
+//	fmt.Print(report)
+func Infer(in Input, index uint32, rows int, opts ...types.InferOpt) (*types.Schema, *types.Report, error) {
+	if rows < 1 {
+		return nil, nil, errors.New("input.Infer sample must be one or more rows")
+	}
+	header, err := in.Header(index)
+	if err != nil {
+		return nil, nil, err
+	}
+	header = append([]string(nil), header...)
+	sample := make([][]string, 0, rows)
+	for len(sample) < rows {
+		row, err := in.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		blank := true
+		for _, v := range row {
+			if v != "" {
+				blank = false
+				break
+			}
+		}
+		if !blank {
+			sample = append(sample, append([]string(nil), row...))
+		}
+	}
+	return types.Infer(header, sample, opts...)
+}
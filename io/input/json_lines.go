@@ -0,0 +1,157 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+type jsonLines struct {
+	source  *bufio.Reader
+	columns []*types.ColumnDefinition
+	header  bool
+	row     int
+	line    int
+	record  []string
+}
+
+// Read the next record.
+// The first record read is built from the schema column names, each following record
+// is a single json object with the values ordered as the schema columns.
+// Lines that are not a json object are logged as defects and skipped.
+func (i *jsonLines) Read() ([]string, error) {
+	if !i.header {
+		i.header = true
+		for ii, c := range i.columns {
+			i.record[ii] = c.Name
+		}
+		return i.record, nil
+	}
+	for {
+		line, err := i.source.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, err
+		}
+		i.line++
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err == io.EOF {
+				return nil, err
+			}
+			continue
+		}
+		i.row++
+		obj := make(map[string]interface{})
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		if er := decoder.Decode(&obj); er != nil {
+			pkg.LogDefect(pkg.Defect{
+				Row: i.row,
+				Col: -1,
+				Msg: fmt.Sprintf("input/json_lines: invalid json object, %s", er.Error()),
+			})
+			i.row--
+			continue
+		}
+		for ii, c := range i.columns {
+			v, found := i.lookup(obj, c)
+			if !found && c.Required {
+				pkg.LogDefect(pkg.Defect{
+					Row: i.row,
+					Col: ii,
+					Msg: fmt.Sprintf("input/json_lines: missing required key %s", c.Name),
+				})
+			}
+			i.record[ii] = v
+		}
+		return i.record, nil
+	}
+}
+
+// Get the line of the last record read, the header built from the schema is line 0
+func (i *jsonLines) Line() int {
+	return i.line
+}
+
+// Find the value of a column by name, then by alias.
+func (i *jsonLines) lookup(obj map[string]interface{}, c *types.ColumnDefinition) (string, bool) {
+	if v, ok := find(obj, c.Name); ok {
+		return stringify(v), true
+	}
+	for _, a := range c.Aliases {
+		if v, ok := find(obj, a); ok {
+			return stringify(v), true
+		}
+	}
+	return "", false
+}
+
+// Find a top level key, or a nested key where each level is separated by a dot
+func find(obj map[string]interface{}, key string) (interface{}, bool) {
+	if v, ok := obj[key]; ok {
+		return v, true
+	}
+	path := strings.Split(key, ".")
+	if len(path) == 1 {
+		return nil, false
+	}
+	var current interface{} = obj
+	for _, p := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[p]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func stringify(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v.(string)
+	case json.Number:
+		return v.(json.Number).String()
+	case bool:
+		return strconv.FormatBool(v.(bool))
+	default:
+		// objects and arrays are kept as their json representation
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+		return fmt.Sprint(v)
+	}
+}
+
+// Create a new json lines (ndjson) input object.
+// Each line of the source is a json object, keys are matched to the schema column names and aliases.
+// Nested keys are addressed with a dotted path, ex. "borrower.name".
+// Required columns missing from an object are logged as defects.
+// Optional properties:
+// 		Charset: character encoding of the source, detected by default
+func JSONLines(source io.Reader, def types.Signature, opts ...Opt) Input {
+	original, err := schema("input.JSONLines", def)
+	if err != nil {
+		return fail(def, err)
+	}
+	src, err := decoded(source, opts)
+	if err != nil {
+		return fail(def, err)
+	}
+	i := new(jsonLines)
+	i.source = bufio.NewReader(src)
+	i.columns = original.Cols()
+	i.record = make([]string, len(i.columns))
+	return Records(i, original)
+}
@@ -0,0 +1,260 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+type (
+	// Native is implemented by inputs holding typed values.
+	// The parser uses a typed value as is when it is of the column's type, the value is not converted from text.
+	Native interface {
+		// Get the typed values of the last record, in the order of the record's fields.
+		// A nil value is read from the text of the field.
+		Values() []interface{}
+	}
+	// typed is a records input over a reader of typed values
+	typed struct {
+		*records
+		native Native
+	}
+	memory struct {
+		rows    reflect.Value
+		columns []*types.ColumnDefinition
+		// field index of each column when the rows are structs, nil when the column is not a field
+		fields [][]int
+		header bool
+		row    int
+		record []string
+		values []interface{}
+	}
+)
+
+// The type of map rows, a named type of the same definition is accepted
+var mapType = reflect.TypeOf(map[string]interface{}(nil))
+
+// Get the typed values of the last record
+func (i *typed) Values() []interface{} {
+	return i.native.Values()
+}
+
+// Create a new input object reading from memory, the mirror of output.Mem.
+// [rows] is a slice of structs, pointers to structs, or map[string]interface{} (or a named type of it).
+// Struct fields are matched to schema columns by the name in the field's `exttra` tag, then by field name;
+// first for the column name and then for each alias. Nested fields and keys are addressed with a dotted path, ex. "Borrower.Name".
+// Values of the column's type are used as is, other values are converted from their text representation.
+//
+// 	type shape struct{
+//		Id     string `exttra:"Loan ID"`
+//		Amount float64
+//	}
+//	in := input.Mem([]shape{{"1001", 250}}, def)
+func Mem(rows interface{}, def types.Signature) Input {
+	original, err := schema("input.Mem", def)
+	if err != nil {
+		return fail(def, err)
+	}
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return fail(def, errors.New("input.Mem rows must be a slice"))
+	}
+	i := new(memory)
+	i.rows = v
+	i.columns = original.Cols()
+	i.record = make([]string, len(i.columns))
+	i.values = make([]interface{}, len(i.columns))
+	elem := v.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	switch {
+	case elem.Kind() == reflect.Struct:
+		i.fields = make([][]int, len(i.columns))
+		missing := make([]string, 0)
+		for ii, c := range i.columns {
+			for _, name := range append([]string{c.Name}, c.Aliases...) {
+				if index, ok := fieldIndex(elem, name); ok {
+					i.fields[ii] = index
+					break
+				}
+			}
+			if i.fields[ii] == nil && c.Required {
+				missing = append(missing, c.Name)
+			}
+		}
+		if len(missing) > 0 {
+			return fail(def, pkg.NewColumnNotFoundException(missing, errors.New(fmt.Sprintf("input.Mem %s has no field for column(s)", elem.Name()))))
+		}
+	case elem.Kind() == reflect.Map && elem.ConvertibleTo(mapType):
+	default:
+		return fail(def, errors.New("input.Mem rows must be structs or map[string]interface{}"))
+	}
+	return Records(i, original)
+}
+
+// Read the next record.
+// The first record read is built from the schema column names.
+func (i *memory) Read() ([]string, error) {
+	if !i.header {
+		i.header = true
+		for ii, c := range i.columns {
+			i.record[ii] = c.Name
+			i.values[ii] = nil
+		}
+		return i.record, nil
+	}
+	// a nil row is skipped, a blank record would end the body
+	var v reflect.Value
+	for {
+		if i.row >= i.rows.Len() {
+			return nil, io.EOF
+		}
+		v = i.rows.Index(i.row)
+		i.row++
+		for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+			break
+		}
+		pkg.LogDefect(pkg.Defect{
+			Row:  i.row,
+			Col:  -1,
+			Line: i.row,
+			Msg:  "input/mem: nil row",
+		})
+	}
+	for ii, c := range i.columns {
+		var (
+			value interface{}
+			found bool
+		)
+		if v.Kind() == reflect.Struct {
+			if found = i.fields[ii] != nil; found {
+				value = field(v, i.fields[ii])
+			}
+		} else {
+			// a named map type is read as the map[string]interface{} it is defined as
+			value, found = lookup(v.Convert(mapType).Interface().(map[string]interface{}), c)
+			if !found && c.Required {
+				pkg.LogDefect(pkg.Defect{
+					Row:  i.row,
+					Col:  ii,
+					Line: i.row,
+					Msg:  fmt.Sprintf("input/mem: missing required key %s", c.Name),
+				})
+			}
+		}
+		i.values[ii] = value
+		i.record[ii] = format(value)
+	}
+	return i.record, nil
+}
+
+// Get the position of the last row in the slice, the header built from the schema is line 0
+func (i *memory) Line() int {
+	return i.row
+}
+
+// Get the typed values of the last record
+func (i *memory) Values() []interface{} {
+	return i.values
+}
+
+// Find the struct field for [name], by tag then by field name. Nested fields are separated by a dot.
+func fieldIndex(t reflect.Type, name string) ([]int, bool) {
+	for ii := 0; ii < t.NumField(); ii++ {
+		f := t.Field(ii)
+		if f.PkgPath != "" {
+			continue
+		}
+		if tag := strings.Split(f.Tag.Get("exttra"), ",")[0]; tag == name || (tag == "" && f.Name == name) {
+			return f.Index, true
+		}
+	}
+	path := strings.SplitN(name, ".", 2)
+	if len(path) == 1 {
+		return nil, false
+	}
+	f, ok := t.FieldByName(path[0])
+	if !ok {
+		return nil, false
+	}
+	ft := f.Type
+	if ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	if ft.Kind() != reflect.Struct {
+		return nil, false
+	}
+	rest, ok := fieldIndex(ft, path[1])
+	if !ok {
+		return nil, false
+	}
+	return append(append([]int(nil), f.Index...), rest...), true
+}
+
+// Get the value of a (nested) field, nil when a pointer on the path is nil
+func field(v reflect.Value, index []int) interface{} {
+	for _, ii := range index {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(ii)
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// Find the value of a column in a map by name, then by alias
+func lookup(obj map[string]interface{}, c *types.ColumnDefinition) (interface{}, bool) {
+	for _, name := range append([]string{c.Name}, c.Aliases...) {
+		if v, ok := find(obj, name); ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Format a typed value as text
+func format(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.(time.Time).Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v.(float64), 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v.(float32)), 'f', -1, 32)
+	case fmt.Stringer:
+		return v.(fmt.Stringer).String()
+	default:
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(rv.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return strconv.FormatUint(rv.Uint(), 10)
+		case reflect.String, reflect.Bool:
+			return fmt.Sprint(v)
+		}
+		return stringify(v)
+	}
+}
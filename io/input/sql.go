@@ -0,0 +1,196 @@
+package input
+
+import (
+	"database/sql"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+// rows reads the result set of a query
+type rows struct {
+	definition *types.Schema
+	rows       *sql.Rows
+	header     []string
+	// schema column of each result column, nil when the result column is not in the schema
+	columns []*types.ColumnDefinition
+	kinds   []string
+	dest    []interface{}
+	record  []string
+	values  []interface{}
+	line    int
+}
+
+// Create a new input object reading the result set [result].
+// Result columns are matched to schema columns by name or alias, as the header of a file.
+// Values are typed with the result's column metadata, values of the column's type are used as is.
+// A NULL is read as an empty field and is handled by the column's pkg.Nullable rules.
+// The result set is closed once exhausted.
+func SQL(result *sql.Rows, def types.Signature) Input {
+	original, err := schema("input.SQL", def)
+	if err != nil {
+		return fail(def, err)
+	}
+	i := new(rows)
+	i.definition = original
+	i.rows = result
+	return i
+}
+
+// Create a new input object running [query] against [db], see [SQL].
+// A failed query is returned by [Input.Header] and [Input.Next].
+func Query(db *sql.DB, query string, def types.Signature, args ...interface{}) Input {
+	result, err := db.Query(query, args...)
+	if err != nil {
+		return fail(def, err)
+	}
+	return SQL(result, def)
+}
+
+// Get the schema of this input
+func (i *rows) GetSchema() types.Signature {
+	return i.definition
+}
+
+// Get the result column names, a result set has a single header row and [index] is ignored.
+func (i *rows) Header(index uint32) ([]string, error) {
+	if i.header != nil {
+		return i.header, nil
+	}
+	cts, err := i.rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	i.header = make([]string, len(cts))
+	i.columns = make([]*types.ColumnDefinition, len(cts))
+	i.kinds = make([]string, len(cts))
+	i.dest = make([]interface{}, len(cts))
+	i.record = make([]string, len(cts))
+	i.values = make([]interface{}, len(cts))
+	for ii, ct := range cts {
+		i.header[ii] = ct.Name()
+		i.kinds[ii] = strings.ToUpper(ct.DatabaseTypeName())
+		i.columns[ii] = i.column(ct.Name())
+		i.dest[ii] = new(interface{})
+	}
+	return i.header, nil
+}
+
+// Get the next row of the result set
+func (i *rows) Next() ([]string, error) {
+	if i.header == nil {
+		if _, err := i.Header(0); err != nil {
+			return nil, err
+		}
+	}
+	if !i.rows.Next() {
+		err := i.rows.Err()
+		_ = i.rows.Close()
+		if err == nil {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	if err := i.rows.Scan(i.dest...); err != nil {
+		return nil, err
+	}
+	i.line++
+	for ii, d := range i.dest {
+		v := *(d.(*interface{}))
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		if c := i.columns[ii]; c != nil {
+			v = coerce(v, c.Field.T)
+		}
+		i.values[ii] = v
+		if t, ok := v.(time.Time); ok && i.kinds[ii] == "DATE" {
+			i.record[ii] = t.Format("2006-01-02")
+		} else {
+			i.record[ii] = format(v)
+		}
+	}
+	return i.record, nil
+}
+
+// Get the number of rows read
+func (i *rows) Line() int {
+	return i.line
+}
+
+// Get the typed values of the last row
+func (i *rows) Values() []interface{} {
+	return i.values
+}
+
+// Find the schema column of a result column by name, then by alias
+func (i *rows) column(name string) *types.ColumnDefinition {
+	for _, c := range i.definition.Cols() {
+		if c.Name == name {
+			return c
+		}
+		for _, a := range c.Aliases {
+			if a == name {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// Convert a driver value to the go type of field type [t] when the conversion is lossless,
+// otherwise the value is returned as is
+func coerce(v interface{}, t pkg.FieldType) interface{} {
+	switch v.(type) {
+	case int64:
+		n := v.(int64)
+		switch t {
+		case pkg.INT32:
+			if n >= math.MinInt32 && n <= math.MaxInt32 {
+				return int32(n)
+			}
+		case pkg.INT16:
+			if n >= math.MinInt16 && n <= math.MaxInt16 {
+				return int16(n)
+			}
+		case pkg.INT8:
+			if n >= math.MinInt8 && n <= math.MaxInt8 {
+				return int8(n)
+			}
+		case pkg.UINT, pkg.UINT64:
+			if n >= 0 {
+				return uint64(n)
+			}
+		case pkg.UINT32:
+			if n >= 0 && n <= math.MaxUint32 {
+				return uint32(n)
+			}
+		case pkg.UINT16:
+			if n >= 0 && n <= math.MaxUint16 {
+				return uint16(n)
+			}
+		case pkg.UINT8:
+			if n >= 0 && n <= math.MaxUint8 {
+				return uint8(n)
+			}
+		case pkg.FLOAT, pkg.FLOAT64:
+			return float64(n)
+		case pkg.BOOL:
+			if n == 0 || n == 1 {
+				return n == 1
+			}
+		}
+	case float64:
+		if t == pkg.FLOAT32 {
+			f := v.(float64)
+			if float64(float32(f)) == f {
+				return float32(f)
+			}
+		}
+	}
+	return v
+}
@@ -0,0 +1,339 @@
+package input
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+type (
+	xlsx struct {
+		rows   [][]string
+		cursor int
+	}
+	xlsxWorkbook struct {
+		Pr struct {
+			Date1904 bool `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			Id   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	xlsxRelationships struct {
+		Relationships []struct {
+			Id     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	xlsxText struct {
+		T string `xml:"t"`
+		R []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	}
+	xlsxSharedStrings struct {
+		Items []xlsxText `xml:"si"`
+	}
+	xlsxStyles struct {
+		NumFmts []struct {
+			Id   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtId int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	xlsxWorksheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				R  string    `xml:"r,attr"`
+				T  string    `xml:"t,attr"`
+				S  int       `xml:"s,attr"`
+				V  string    `xml:"v"`
+				Is *xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+)
+
+// Read the next row of the sheet.
+// Rows missing from the sheet are returned as empty records, so the record index
+// is always the zero based row index of the sheet.
+func (i *xlsx) Read() ([]string, error) {
+	if i.cursor >= len(i.rows) {
+		return nil, io.EOF
+	}
+	row := i.rows[i.cursor]
+	i.cursor++
+	return row, nil
+}
+
+// Get the one based sheet row of the last record read
+func (i *xlsx) Line() int {
+	return i.cursor
+}
+
+func (t xlsxText) String() string {
+	if len(t.R) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+// Create a new excel workbook (xlsx) input object.
+// [sheet] selects the worksheet either by name (string) or by zero based position (int).
+// Shared, inline and formula strings are read as text, numeric cells formatted as a date are
+// converted from the excel serial date to an ISO 8601 date.
+// The header row is selected with [parser.Validate] where the index is the zero based row of the sheet.
+func Xlsx(source io.Reader, sheet interface{}, def types.Signature) Input {
+	original, err := schema("input.Xlsx", def)
+	if err != nil {
+		return fail(def, err)
+	}
+	i := new(xlsx)
+	rows, err := readWorkbook(source, sheet)
+	if err != nil {
+		return fail(def, err)
+	}
+	i.rows = rows
+	return Records(i, original)
+}
+
+func readWorkbook(source io.Reader, sheet interface{}) ([][]string, error) {
+	var (
+		workbook xlsxWorkbook
+		rels     xlsxRelationships
+		shared   xlsxSharedStrings
+		styles   xlsxStyles
+		ws       xlsxWorksheet
+		target   string
+	)
+	b, err := ioutil.ReadAll(source)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	if err = unmarshalMember(files, "xl/workbook.xml", &workbook, true); err != nil {
+		return nil, err
+	}
+	if err = unmarshalMember(files, "xl/_rels/workbook.xml.rels", &rels, true); err != nil {
+		return nil, err
+	}
+	if err = unmarshalMember(files, "xl/sharedStrings.xml", &shared, false); err != nil {
+		return nil, err
+	}
+	if err = unmarshalMember(files, "xl/styles.xml", &styles, false); err != nil {
+		return nil, err
+	}
+	rId := ""
+	switch sheet.(type) {
+	case string:
+		for _, s := range workbook.Sheets {
+			if s.Name == sheet.(string) {
+				rId = s.Id
+				break
+			}
+		}
+	case int:
+		if idx := sheet.(int); idx >= 0 && idx < len(workbook.Sheets) {
+			rId = workbook.Sheets[idx].Id
+		}
+	default:
+		return nil, errors.New("input/xlsx: sheet must be a name (string) or index (int)")
+	}
+	if rId == "" {
+		return nil, errors.New(fmt.Sprintf("input/xlsx: sheet %v not found", sheet))
+	}
+	for _, r := range rels.Relationships {
+		if r.Id == rId {
+			target = r.Target
+			break
+		}
+	}
+	if strings.HasPrefix(target, "/") {
+		target = strings.TrimPrefix(target, "/")
+	} else {
+		target = path.Join("xl", target)
+	}
+	if err = unmarshalMember(files, target, &ws, true); err != nil {
+		return nil, err
+	}
+	dates := dateStyles(styles)
+	width := 0
+	cells := make(map[int]map[int]string)
+	last := 0
+	for _, r := range ws.Rows {
+		rowIdx := r.R
+		if rowIdx == 0 {
+			rowIdx = last + 1
+		}
+		last = rowIdx
+		row := make(map[int]string)
+		colIdx := -1
+		for _, c := range r.Cells {
+			if c.R != "" {
+				colIdx = columnIndex(c.R)
+			} else {
+				colIdx++
+			}
+			var v string
+			switch c.T {
+			case "s":
+				si, err := strconv.Atoi(c.V)
+				if err != nil || si < 0 || si >= len(shared.Items) {
+					return nil, errors.New(fmt.Sprintf("input/xlsx: invalid shared string at %s", c.R))
+				}
+				v = shared.Items[si].String()
+			case "inlineStr":
+				if c.Is != nil {
+					v = c.Is.String()
+				}
+			case "b":
+				if c.V == "1" {
+					v = "TRUE"
+				} else {
+					v = "FALSE"
+				}
+			case "str", "e":
+				v = c.V
+			default:
+				v = c.V
+				if f, err := strconv.ParseFloat(c.V, 64); err == nil {
+					if c.S >= 0 && c.S < len(styles.CellXfs) && dates[styles.CellXfs[c.S].NumFmtId] {
+						v = serialDate(f, workbook.Pr.Date1904)
+					} else {
+						v = strconv.FormatFloat(f, 'f', -1, 64)
+					}
+				}
+			}
+			row[colIdx] = v
+			if colIdx+1 > width {
+				width = colIdx + 1
+			}
+		}
+		cells[rowIdx] = row
+	}
+	rows := make([][]string, last)
+	for ri := range rows {
+		rows[ri] = make([]string, width)
+		for ci, v := range cells[ri+1] {
+			rows[ri][ci] = v
+		}
+	}
+	return rows, nil
+}
+
+func unmarshalMember(files map[string]*zip.File, name string, v interface{}, required bool) error {
+	f, ok := files[name]
+	if !ok {
+		if required {
+			return errors.New(fmt.Sprintf("input/xlsx: workbook member %s not found", name))
+		}
+		return nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// Convert a cell reference (ex. "AB12") to a zero based column index
+func columnIndex(ref string) int {
+	idx := 0
+	for _, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			idx = idx*26 + int(r-'A') + 1
+		} else if r >= 'a' && r <= 'z' {
+			idx = idx*26 + int(r-'a') + 1
+		} else {
+			break
+		}
+	}
+	return idx - 1
+}
+
+// Number formats which represent a date or time
+func dateStyles(styles xlsxStyles) map[int]bool {
+	dates := map[int]bool{14: true, 15: true, 16: true, 17: true, 18: true, 19: true, 20: true, 21: true, 22: true,
+		45: true, 46: true, 47: true}
+	for _, f := range styles.NumFmts {
+		dates[f.Id] = isDateFormat(f.Code)
+	}
+	return dates
+}
+
+// A custom number format is a date when it holds a year, day, hour or second token outside
+// of literals, escapes and bracketed sections (colors, conditions)
+func isDateFormat(code string) bool {
+	var b strings.Builder
+	for ii := 0; ii < len(code); ii++ {
+		switch c := code[ii]; c {
+		case '"':
+			if end := strings.IndexByte(code[ii+1:], '"'); end < 0 {
+				ii = len(code)
+			} else {
+				ii += end + 1
+			}
+		case '\\', '_', '*':
+			ii++
+		case '[':
+			end := strings.IndexByte(code[ii:], ']')
+			if end < 0 {
+				ii = len(code)
+				break
+			}
+			// keep elapsed time tokens ex. [h]
+			if token := strings.ToLower(code[ii+1 : ii+end]); token != "" && strings.Trim(token, "hms") == "" {
+				b.WriteString(token)
+			}
+			ii += end
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return strings.ContainsAny(strings.ToLower(b.String()), "ydhs")
+}
+
+// Convert an excel serial date to an ISO 8601 date, or date time when the serial has a time component
+func serialDate(serial float64, date1904 bool) string {
+	epoch := time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC)
+	} else if serial < 61 {
+		// excel treats 1900 as a leap year, serials before 1900-03-01 are off by one day
+		epoch = epoch.AddDate(0, 0, 1)
+	}
+	days := math.Floor(serial)
+	ms := math.Round((serial - days) * 24 * 60 * 60 * 1000)
+	t := epoch.AddDate(0, 0, int(days)).Add(time.Duration(ms) * time.Millisecond)
+	if ms == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339)
+}
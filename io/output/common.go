@@ -1,6 +1,7 @@
 package output
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -15,6 +16,9 @@ type (
 		// In the case of memory the output is an out parameter passed during initialization.
 		// See output.[Mem] for more information
 		Flush() error
+		// Flush the value of an exttra tree, see [Out.Flush].
+		// Writing stops once [ctx] is done, the goroutines building the output are released and the context's error is returned.
+		FlushContext(ctx context.Context) error
 		base() *output
 	}
 	addOnArgItem struct {
@@ -26,6 +30,8 @@ type (
 		addOns     []addOnArgItem
 		addOnArgs  map[string]interface{}
 		formatters map[uint64]CustomFormatter
+		// construction error, returned by Flush
+		err error
 	}
 	Memory struct {
 		output
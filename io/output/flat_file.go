@@ -2,10 +2,12 @@ package output
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
 	"errors"
-	"log"
 	"os"
+	"sort"
+	"time"
 
 	"github.com/loanpal-engineering/exttra/types"
 
@@ -18,7 +20,7 @@ import (
 // 		AddOn: append an additional column where the value is created from the function response
 // 		Alias: add a display name for the column specified in the Alias parameter
 // Returns a FlatFile object. Nothing has been written at this point.
-// To write call [Flush]
+// To write call [Flush], an invalid destination or option is returned by [Flush]
 func Csv(data pkg.Composer, dest interface{}, opts ...Opt) Out {
 	i := new(FlatFile)
 	i.src = data
@@ -29,13 +31,15 @@ func Csv(data pkg.Composer, dest interface{}, opts ...Opt) Out {
 	i.alias = make(map[uint64]string)
 	i.formatters = make(map[uint64]CustomFormatter)
 	if pkg.IsNil(dest) {
-		log.Fatal("io/output/csv: destination can not be nil")
+		i.err = pkg.NewOutputException("io/output/csv: destination can not be nil", nil)
+		return i
 	}
 	i.dest = dest
 	for _, o := range opts {
 		ii, er := o(i)
 		if er != nil {
-			log.Fatal(er)
+			i.err = pkg.NewOutputException("io/output/csv: invalid option", er)
+			return i
 		}
 		i = ii.(*FlatFile)
 	}
@@ -55,22 +59,35 @@ func (i *FlatFile) Header() (error, []string) {
 // flushes node to all destinations
 // destination files, buffers, and error (if any) are returned
 func (i *FlatFile) Flush() error {
+	return i.FlushContext(context.Background())
+}
+
+// Flush to all destinations, see [FlatFile.Flush].
+// Flushing stops once [ctx] is done and the context's error is returned, a destination file may be partially written.
+func (i *FlatFile) FlushContext(ctx context.Context) error {
 	var (
 		writer *csv.Writer
 	)
+	if i.err != nil {
+		return i.err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	switch i.dest.(type) {
 	case string:
 		strDest := i.dest.(string)
 		if f, err := os.Create(strDest); err != nil {
-			log.Fatal(err)
+			return pkg.NewOutputException("io/output/csv: can not create "+strDest, err)
 		} else {
+			defer f.Close()
 			writer = csv.NewWriter(f)
 		}
 	case *bytes.Buffer:
 		bufDest := i.dest.(*bytes.Buffer)
 		writer = csv.NewWriter(bufDest)
 	default:
-		log.Fatal("output.Flush unknown destination type")
+		return pkg.NewOutputException("output.Flush unknown destination type", nil)
 	}
 
 	root := i.src
@@ -81,29 +98,47 @@ func (i *FlatFile) Flush() error {
 		}
 	}
 	columns := make([][]string, l) // iterate over columns
-	column := make(chan pkg.Pair)
+	// buffered so column goroutines are never blocked once flushing stops
+	column := make(chan pkg.Pair, len(*root.Children()))
 	sent := 0
 	complete := 0
+	// columns are written in the order of the input
+	visible := make([]pkg.Composer, 0, len(*root.Children()))
 	for id, v := range *root.Children() {
 		if pkg.IsNil(v) || root.Null()[id] {
 			continue
 		}
-		go i.buildColumn(column, v, sent)
+		visible = append(visible, v)
+	}
+	sort.Slice(visible, func(a, b int) bool {
+		_, ca, _ := visible[a].Id()
+		_, cb, _ := visible[b].Id()
+		return ca < cb
+	})
+	for _, v := range visible {
+		go i.buildColumn(ctx, column, v, sent)
 		sent++
 	}
+	if sent == 0 {
+		return pkg.NewOutputException("io/output/csv: no columns to write", nil)
+	}
 	for {
 		select {
+		case <-ctx.Done():
+			return ctx.Err()
 		case r := <-column:
 			complete++
 			idx := r.Second.(int)
 			colRow := r.First.([]string)
 			columns[idx] = colRow
 			if sent == complete {
-				rows := i.buildRows(columns)
-				i.header = rows[0]
-				err := writer.WriteAll(rows)
+				rows, err := i.buildRows(columns)
 				if err != nil {
-					pkg.FatalDefect(pkg.Defect{
+					return err
+				}
+				i.header = rows[0]
+				if err = writer.WriteAll(rows); err != nil {
+					return pkg.FatalDefect(pkg.Defect{
 						Msg: err.Error(),
 					})
 				}
@@ -113,7 +148,7 @@ func (i *FlatFile) Flush() error {
 		}
 	}
 }
-func (i *FlatFile) buildRows(cols [][]string) [][]string {
+func (i *FlatFile) buildRows(cols [][]string) ([][]string, error) {
 	var length *int = nil
 	for _, c := range cols {
 		if c == nil {
@@ -121,14 +156,14 @@ func (i *FlatFile) buildRows(cols [][]string) [][]string {
 		}
 		if length != nil {
 			if len(c) != *length {
-				log.Fatal("variable length columns not supported")
+				return nil, pkg.NewOutputException("io/output/csv: variable length columns not supported", nil)
 			}
 		}
 		l := len(c)
 		length = &l
 	}
 	if length == nil {
-		log.Fatal("output/csv: can not build rows with empty columns")
+		return nil, pkg.NewOutputException("output/csv: can not build rows with empty columns", nil)
 	}
 	rows := make([][]string, 0)
 	for ii := 0; ii < *length; ii++ {
@@ -156,9 +191,9 @@ func (i *FlatFile) buildRows(cols [][]string) [][]string {
 		rows = append(rows, row)
 		// rows[ii] = row
 	}
-	return rows
+	return rows, nil
 }
-func (i *FlatFile) buildColumn(out chan pkg.Pair, n pkg.Composer, colIdx int) {
+func (i *FlatFile) buildColumn(ctx context.Context, out chan pkg.Pair, n pkg.Composer, colIdx int) {
 	val := make([]string, n.Max()+2) // add one row for headers, and one as the Max value(row) must be inclusive, ex. if max = 10, then val[10] must not be out of range.
 	id, _, _ := n.Id()
 	if alias, ok := i.alias[id]; ok {
@@ -171,7 +206,13 @@ func (i *FlatFile) buildColumn(out chan pkg.Pair, n pkg.Composer, colIdx int) {
 		format = f
 	}
 	excludes := i.src.(pkg.Editor).Excludes()
+	built := 0
 	for _, v := range *n.Children() {
+		if built++; built%1024 == 0 && ctx.Err() != nil {
+			// the column is discarded, Flush has returned
+			out <- pkg.Pair{First: val, Second: colIdx}
+			return
+		}
 		_, _, row := v.Id()
 		if excludes[row] {
 			continue
@@ -187,6 +228,11 @@ func (i *FlatFile) buildColumn(out chan pkg.Pair, n pkg.Composer, colIdx int) {
 			continue
 		} else {
 			vv := types.SimpleToString(v.Value())
+			if t, ok := v.Value().(time.Time); ok && n.T() == pkg.DATE {
+				// a date is written without its time of day
+				date := t.Format(types.DateLayout)
+				vv = &date
+			}
 			if vv == nil || *vv == "" {
 				if !pkg.IsNil(n.Nullable()) && n.Nullable().ReplaceWith != nil {
 					val[row+1] = *n.Nullable().ReplaceWith
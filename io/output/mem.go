@@ -1,6 +1,7 @@
 package output
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -9,9 +10,12 @@ import (
 	"time"
 
 	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
 )
 
-// "Write" an exttra tree to memory in a predefined shape
+// "Write" an exttra tree to memory in a predefined shape.
+// A column without an Alias is written to the field tagged with the column name (`exttra:"Loan ID"`),
+// or to the field of the same name, see types.SchemaFromStruct
 //
 // 	type shape struct{
 //		A string
@@ -32,18 +36,21 @@ func Mem(data pkg.Composer, shape interface{}, outParam *[]interface{}, opts ...
 	i := new(Memory)
 	i.src = data
 	i.shape = shape
-	if err := i.assertShape(); err != nil {
-		return nil
-	}
 	i.out = outParam
 	i.alias = make(map[uint64]reflect.StructField)
+	if err := i.assertShape(); err != nil {
+		i.err = err
+		return i
+	}
 	for _, o := range opts {
 		ii, er := o(i)
 		if er != nil {
-			log.Fatal(er)
+			i.err = pkg.NewOutputException("output/Memory: invalid option", er)
+			return i
 		}
 		i = ii.(*Memory)
 	}
+	i.autoAlias()
 	return i
 }
 func (i *Memory) base() *output { return &i.output }
@@ -56,8 +63,7 @@ func (i *Memory) leftMostNode() (pkg.Composer, error) {
 		break
 	}
 	if lhs == nil {
-		// todo: better error
-		log.Fatal()
+		return nil, pkg.NewOutputException("output/Memory: no columns to write", nil)
 	}
 	// Get the left most node
 	for {
@@ -74,8 +80,7 @@ func (i *Memory) leftMostNode() (pkg.Composer, error) {
 			if lhs.Next() != nil {
 				lhs = lhs.Next()
 			} else {
-				// todo: better error
-				log.Fatal()
+				return nil, pkg.NewOutputException("output/Memory: no visible columns to write", nil)
 			}
 		} else {
 			break
@@ -87,13 +92,24 @@ func (i *Memory) leftMostNode() (pkg.Composer, error) {
 	return lhs, nil
 }
 func (i *Memory) Flush() error {
+	return i.FlushContext(context.Background())
+}
+
+// Write the tree to the out parameter, see [Memory.Flush].
+// Flushing stops once [ctx] is done and the context's error is returned, rows completed before are kept in the out parameter.
+func (i *Memory) FlushContext(ctx context.Context) error {
 	var (
-		lhs     pkg.Composer
-		err     error = nil
-		quitter       = make(chan error)
-		entity        = make(chan interface{})
-		sent          = 0
+		lhs  pkg.Composer
+		err  error = nil
+		rows       = make([]pkg.Composer, 0)
+		sent       = 0
 	)
+	if i.err != nil {
+		return i.err
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
 	if lhs, err = i.leftMostNode(); err != nil {
 		return err
 	}
@@ -103,14 +119,24 @@ func (i *Memory) Flush() error {
 		if excludes[row] {
 			continue
 		}
-		go i.fillShape(entity, quitter, v, excludes)
-		sent++
+		rows = append(rows, v)
 	}
-	if sent == 0 {
+	if len(rows) == 0 {
 		return nil
 	}
+	// buffered so row goroutines are never blocked once flushing stops
+	var (
+		quitter = make(chan error, len(rows))
+		entity  = make(chan interface{}, len(rows))
+	)
+	for _, v := range rows {
+		go i.fillShape(ctx, entity, quitter, v, excludes)
+		sent++
+	}
 	for {
 		select {
+		case <-ctx.Done():
+			return ctx.Err()
 		case r := <-entity:
 			sent--
 			*i.out = append(*i.out, r)
@@ -128,38 +154,65 @@ func (i *Memory) Flush() error {
 // Use find field only for nested properties, otherwise use `elem.Type().FieldByName(name)`
 func (i *Memory) findField(name string) (reflect.StructField, error) {
 	var (
-		path = strings.Split(name, ".")
-		v    = reflect.ValueOf(i.shape)
+		path  = strings.Split(name, ".")
+		t     = reflect.TypeOf(i.shape)
+		index = make([]int, 0, len(path))
 	)
-loop:
-	f := v.FieldByName(path[0])
-	if f.Kind() == reflect.Struct && len(path) > 1 {
-		v = f
-		path = path[1:]
-		goto loop
-	} else if len(path) == 1 {
-		if pi, ok := v.Type().FieldByName(path[0]); !ok {
+	for ii, p := range path {
+		f, ok := t.FieldByName(p)
+		if !ok {
 			return reflect.StructField{}, errors.New(fmt.Sprintf("output/Memory: can not find field %s", name))
-		} else {
-			return pi, nil
 		}
-	} else {
-		return reflect.StructField{}, errors.New(fmt.Sprintf("output/Memory: failed to set shape property %s", name))
+		// the index of a nested field is relative to the shape
+		index = append(index, f.Index...)
+		if ii == len(path)-1 {
+			f.Index = index
+			return f, nil
+		}
+		if f.Type.Kind() != reflect.Struct {
+			break
+		}
+		t = f.Type
+	}
+	return reflect.StructField{}, errors.New(fmt.Sprintf("output/Memory: failed to set shape property %s", name))
+}
+
+// Alias the columns without an alias to the fields of the shape, by the field's `exttra` tag and then by field name.
+// See types.SchemaFromStruct
+func (i *Memory) autoAlias() {
+	// a shape that is not a valid schema (ex. a field of an unsupported type) is aliased by field name
+	fields, _ := types.StructColumns(i.shape)
+	for _, col := range *i.src.Children() {
+		id, _, _ := col.Id()
+		if _, ok := i.alias[id]; ok {
+			continue
+		}
+		path, ok := fields[col.Name()]
+		if !ok {
+			path = col.Name()
+		}
+		if prop, err := i.findField(path); err == nil {
+			i.alias[id] = prop
+		}
 	}
 }
 func (i *Memory) assertShape() error {
 	if reflect.ValueOf(i.shape).Kind() != reflect.Struct {
-		return errors.New("output/Memory: shape must be a struct")
+		return pkg.NewOutputException("output/Memory: shape must be a struct", nil)
 	}
 	return nil
 }
 
 // fill the shape representing a single row, n MUST be the left most node that is part of the shape.
-func (i *Memory) fillShape(out chan interface{}, quit chan error, n pkg.Composer, excludes []bool) {
+func (i *Memory) fillShape(ctx context.Context, out chan interface{}, quit chan error, n pkg.Composer, excludes []bool) {
 	var (
 		orig = reflect.ValueOf(i.shape)
 		cpy  = reflect.New(orig.Type()).Elem()
 	)
+	if err := ctx.Err(); err != nil {
+		quit <- err
+		return
+	}
 	for {
 		var (
 			field reflect.StructField
@@ -173,7 +226,8 @@ func (i *Memory) fillShape(out chan interface{}, quit chan error, n pkg.Composer
 		if alias, ok := i.alias[cId]; ok {
 			field = alias
 		} else {
-			quit <- errors.New(fmt.Sprintf("output/Memory: alias not found for column %d", cId))
+			quit <- pkg.NewOutputException(fmt.Sprintf("output/Memory: alias not found for column %d", cId), nil)
+			return
 		}
 		if pkg.IsNil(n.Value()) {
 			goto next
@@ -182,63 +236,9 @@ func (i *Memory) fillShape(out chan interface{}, quit chan error, n pkg.Composer
 			// }
 		}
 		// make sure n.Value is the same type as the field
-		switch n.Value().(type) {
-		case string:
-			if field.Type.Kind() == reflect.String {
-				cpy.FieldByIndex(field.Index).SetString(n.Value().(string))
-			}
-		case float64:
-			if field.Type.Kind() == reflect.Float64 {
-				cpy.FieldByIndex(field.Index).SetFloat(n.Value().(float64))
-			}
-		case float32:
-			if field.Type.Kind() == reflect.Float32 {
-				cpy.FieldByIndex(field.Index).Set(reflect.ValueOf(n.Value().(float32)))
-			}
-		case bool:
-			if field.Type.Kind() == reflect.Bool {
-				cpy.FieldByIndex(field.Index).SetBool(n.Value().(bool))
-			}
-		case time.Time:
-			if field.Type.Kind() != reflect.Struct {
-				cpy.FieldByIndex(field.Index).SetInt(n.Value().(time.Time).Unix())
-			} else {
-				cpy.FieldByIndex(field.Index).Set(reflect.ValueOf(n.Value()))
-			}
-		case int64:
-			if field.Type.Kind() == reflect.Int64 {
-				cpy.FieldByIndex(field.Index).SetInt(n.Value().(int64))
-			}
-		case int32:
-			if field.Type.Kind() == reflect.Int32 {
-				cpy.FieldByIndex(field.Index).Set(reflect.ValueOf(n.Value().(int32)))
-			}
-		case int16:
-			if field.Type.Kind() == reflect.Int16 {
-				cpy.FieldByIndex(field.Index).Set(reflect.ValueOf(n.Value().(int16)))
-			}
-		case int8:
-			if field.Type.Kind() == reflect.Int8 {
-				cpy.FieldByIndex(field.Index).Set(reflect.ValueOf(n.Value().(int8)))
-			}
-		case uint64:
-			if field.Type.Kind() == reflect.Uint64 {
-				cpy.FieldByIndex(field.Index).SetUint(n.Value().(uint64))
-			}
-		case uint32:
-			if field.Type.Kind() == reflect.Uint32 {
-				cpy.FieldByIndex(field.Index).Set(reflect.ValueOf(n.Value().(uint32)))
-			}
-		case uint16:
-			if field.Type.Kind() == reflect.Uint16 {
-				cpy.FieldByIndex(field.Index).Set(reflect.ValueOf(n.Value().(uint16)))
-			}
-		case uint8:
-			if field.Type.Kind() == reflect.Uint8 {
-				cpy.FieldByIndex(field.Index).Set(reflect.ValueOf(n.Value().(uint8)))
-			}
-		default:
-			quit <- errors.New("output/Memory: unknown type, supported types are: string, float64, int64, bool, time.Time")
+		if !set(cpy.FieldByIndex(field.Index), n.Value()) {
+			quit <- pkg.NewOutputException("output/Memory: unknown type, supported types are: string, float64, int64, bool, time.Time", nil)
+			return
 		}
 	next:
 		if n.Next() != nil {
@@ -249,3 +249,101 @@ func (i *Memory) fillShape(out chan interface{}, quit chan error, n pkg.Composer
 	}
 	out <- cpy.Interface()
 }
+
+// Set [dst] to [v] when the kinds match, a pointer field is set to a new value.
+// False is returned when the type of [v] is not supported.
+func set(dst reflect.Value, v interface{}) bool {
+	if dst.Kind() == reflect.Ptr {
+		elem := reflect.New(dst.Type().Elem())
+		if !set(elem.Elem(), v) {
+			return false
+		}
+		// a field of another kind is left nil
+		if !elem.Elem().IsZero() || reflect.ValueOf(v).IsZero() {
+			dst.Set(elem)
+		}
+		return true
+	}
+	kind := dst.Kind()
+	switch v.(type) {
+	case string:
+		if kind == reflect.String {
+			dst.SetString(v.(string))
+		}
+	case float64:
+		if kind == reflect.Float64 {
+			dst.SetFloat(v.(float64))
+		}
+	case float32:
+		if kind == reflect.Float32 {
+			dst.Set(reflect.ValueOf(v.(float32)))
+		}
+	case bool:
+		if kind == reflect.Bool {
+			dst.SetBool(v.(bool))
+		}
+	case time.Time:
+		if kind == reflect.Int64 || kind == reflect.Int {
+			dst.SetInt(v.(time.Time).Unix())
+		} else if dst.Type() == reflect.TypeOf(v) {
+			dst.Set(reflect.ValueOf(v))
+		}
+	case pkg.Decimal:
+		// a decimal is written exactly to a decimal or a string, a float is the closest float
+		switch {
+		case dst.Type() == reflect.TypeOf(v):
+			dst.Set(reflect.ValueOf(v))
+		case kind == reflect.String:
+			dst.SetString(v.(pkg.Decimal).String())
+		case kind == reflect.Float64:
+			dst.SetFloat(v.(pkg.Decimal).Float64())
+		}
+	case pkg.Category:
+		// a category is written as its value to a string, and as its code to an integer
+		switch {
+		case dst.Type() == reflect.TypeOf(v):
+			dst.Set(reflect.ValueOf(v))
+		case kind == reflect.String:
+			dst.SetString(v.(pkg.Category).String())
+		case kind >= reflect.Int && kind <= reflect.Int64:
+			dst.SetInt(int64(v.(pkg.Category).Code))
+		case kind >= reflect.Uint && kind <= reflect.Uint64:
+			dst.SetUint(uint64(v.(pkg.Category).Code))
+		}
+	case int64:
+		if kind == reflect.Int64 || kind == reflect.Int {
+			dst.SetInt(v.(int64))
+		}
+	case int32:
+		if kind == reflect.Int32 {
+			dst.Set(reflect.ValueOf(v.(int32)))
+		}
+	case int16:
+		if kind == reflect.Int16 {
+			dst.Set(reflect.ValueOf(v.(int16)))
+		}
+	case int8:
+		if kind == reflect.Int8 {
+			dst.Set(reflect.ValueOf(v.(int8)))
+		}
+	case uint64:
+		if kind == reflect.Uint64 || kind == reflect.Uint {
+			dst.SetUint(v.(uint64))
+		}
+	case uint32:
+		if kind == reflect.Uint32 {
+			dst.Set(reflect.ValueOf(v.(uint32)))
+		}
+	case uint16:
+		if kind == reflect.Uint16 {
+			dst.Set(reflect.ValueOf(v.(uint16)))
+		}
+	case uint8:
+		if kind == reflect.Uint8 {
+			dst.Set(reflect.ValueOf(v.(uint8)))
+		}
+	default:
+		return false
+	}
+	return true
+}
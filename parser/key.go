@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+type (
+	// A key checked while parsing, see [types.Key]
+	constraint struct {
+		def *types.KeyDefinition
+		// a unique column, duplicates are reported as duplicate ids
+		implicit bool
+		// id of the unique column of an implicit key, only its cell of a duplicate row is hidden
+		column uint64
+		// record positions and names of the key columns
+		fields []int
+		names  []string
+		// first (or kept) row of each key tuple
+		seen map[string]*occurrence
+	}
+	occurrence struct {
+		row    uint32
+		line   int
+		source string
+		// every row of the key has been excluded, see [types.RejectAll]
+		rejected bool
+	}
+)
+
+// Separates the values of a composite key tuple
+const keySep = "\x1f"
+
+// Build the key constraints of the bound columns; a unique column is a single column key keeping the first row.
+// Key columns are added to the primary columns so the defects of a row carry the row's key values.
+func (p *parser) constrain(def *types.Schema) error {
+	p.constraints = make([]*constraint, 0, len(p.primary)+len(def.Keys()))
+	for _, id := range p.primary {
+		col := p.data.FindById(id)
+		if pkg.IsNil(col) {
+			continue
+		}
+		_, colIdx, _ := col.Id()
+		p.constraints = append(p.constraints, &constraint{
+			def:      &types.KeyDefinition{Name: col.Name(), Columns: []string{col.Name()}, Policy: types.KeepFirst},
+			implicit: true,
+			column:   id,
+			fields:   []int{int(colIdx)},
+			names:    []string{col.Name()},
+			seen:     make(map[string]*occurrence),
+		})
+	}
+	for _, k := range def.Keys() {
+		c := &constraint{def: k, seen: make(map[string]*occurrence)}
+		for _, name := range k.Columns {
+			col := p.data.Find(name)
+			if pkg.IsNil(col) {
+				return pkg.NewSchemaException(name, fmt.Sprintf("column of key %s not found in the input", k.Name))
+			}
+			id, colIdx, _ := col.Id()
+			c.fields = append(c.fields, int(colIdx))
+			c.names = append(c.names, name)
+			if !p.isPrimary(id) {
+				p.primary = append(p.primary, id)
+			}
+		}
+		p.constraints = append(p.constraints, c)
+	}
+	return nil
+}
+
+func (p *parser) isPrimary(id uint64) bool {
+	for _, pi := range p.primary {
+		if pi == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Check the keys of a committed record, a duplicate is logged and rows are excluded following the key's policy
+func (p *parser) keyed(r *record) error {
+	for _, c := range p.constraints {
+		var (
+			values = make([]string, len(c.fields))
+			keys   = make(map[string]string, len(c.fields))
+		)
+		for ii, f := range c.fields {
+			if f < len(r.fields) {
+				values[ii] = strings.TrimSpace(r.fields[f])
+			}
+			keys[c.names[ii]] = values[ii]
+		}
+		tuple := strings.Join(values, keySep)
+		first, exists := c.seen[tuple]
+		if !exists {
+			c.seen[tuple] = &occurrence{row: r.row, line: r.line, source: r.source}
+			continue
+		}
+		d := r.defect(c.fields[0])
+		d.Keys = keys
+		if c.implicit {
+			d.Msg = fmt.Sprintf("Duplicate id [%s]", values[0])
+		} else {
+			d.Msg = fmt.Sprintf("Duplicate key %s [%s]", c.def.Name, strings.Join(values, ","))
+		}
+		switch c.def.Policy {
+		case types.KeepFirst:
+			if c.implicit {
+				p.hide(c.column, r.row)
+			} else {
+				p.exclude(r.row)
+			}
+		case types.KeepLast:
+			// the defect is on the excluded row
+			d.Msg = fmt.Sprintf("%s, replaced by row %d", d.Msg, r.row)
+			d.Row, d.Line, d.Source = int(first.row), first.line, first.source
+			p.exclude(first.row)
+			c.seen[tuple] = &occurrence{row: r.row, line: r.line, source: r.source}
+		case types.RejectAll:
+			p.exclude(r.row)
+			if !first.rejected {
+				first.rejected = true
+				p.exclude(first.row)
+				fd := pkg.Defect{
+					Row:    int(first.row),
+					Col:    c.fields[0],
+					Line:   first.line,
+					Source: first.source,
+					Keys:   keys,
+					Msg:    d.Msg,
+				}
+				pkg.LogDefect(fd)
+			}
+		}
+		pkg.LogDefect(d)
+	}
+	return nil
+}
+
+// Hide the cell of body row [row] in column [id], the other cells of the row are kept.
+// A row of a previous batch has been handed off and is left as is.
+func (p *parser) hide(id uint64, row uint32) {
+	if row <= p.base {
+		return
+	}
+	col := p.data.FindById(id)
+	if pkg.IsNil(col) {
+		return
+	}
+	_, colIdx, _ := col.Id()
+	col.(pkg.Editor).Toggle(pkg.GenNodeId(colIdx, row-p.base), true)
+}
+
+// Exclude body row [row] from the output, the row is excluded from the tree and its cells in the non-nullable
+// columns are hidden, see [pkg.Editor.Excludes]. A row of a previous batch has been handed off and is left as is.
+func (p *parser) exclude(row uint32) {
+	if row <= p.base {
+		return
+	}
+	p.data.(pkg.Editor).Exclude(row - p.base)
+	for _, col := range *p.data.Children() {
+		if col.Nullable().Allowed {
+			continue
+		}
+		_, colIdx, _ := col.Id()
+		col.(pkg.Editor).Toggle(pkg.GenNodeId(colIdx, row-p.base), true)
+	}
+}
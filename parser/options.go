@@ -0,0 +1,186 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+type (
+	Opt func(*parser) (*parser, error)
+
+	// Match a trailer row
+	TrailerMatch func(row []string) bool
+	// Validate a trailer row, [count] is the number of records parsed before the trailer
+	TrailerCheck func(row []string, count int) error
+)
+
+// Discard [n] leading records (title lines, report preamble) before searching for the header.
+func Skip(n uint32) Opt {
+	return func(p *parser) (*parser, error) {
+		p.skip = n
+		return p, nil
+	}
+}
+
+// Detect the header row by matching schema columns.
+// The first of the next [limit] records holding every required column (by name or alias) is used as the header.
+// The index passed to [Validate] is ignored when the header is detected.
+func DetectHeader(limit uint32) Opt {
+	return func(p *parser) (*parser, error) {
+		if limit == 0 {
+			return nil, errors.New("parser/options: header detection requires a limit")
+		}
+		p.detect = limit
+		return p, nil
+	}
+}
+
+// The header spans [n] rows. Rows are merged into one header, the non empty cells of each column are
+// joined with a space. Empty cells of all but the last header row take the value of the cell to the left,
+// as merged cells spanning several columns are exported.
+func HeaderRows(n uint32) Opt {
+	return func(p *parser) (*parser, error) {
+		if n == 0 {
+			return nil, errors.New("parser/options: header rows must be > 0")
+		}
+		p.headerRows = n
+		return p, nil
+	}
+}
+
+// Skip blank rows. By default parsing stops at the first blank row.
+func SkipBlankRows() Opt {
+	return func(p *parser) (*parser, error) {
+		p.skipBlank = true
+		return p, nil
+	}
+}
+
+// Stop parsing at the first row matching [match], the row is a trailer (summary, totals) and is not parsed.
+// When [check] is not nil the trailer is validated, a failed check is returned as the error of [Parse].
+func Trailer(match TrailerMatch, check TrailerCheck) Opt {
+	return func(p *parser) (*parser, error) {
+		if match == nil {
+			return nil, errors.New("parser/options: trailer requires a match function")
+		}
+		p.trailer = match
+		p.trailerCheck = check
+		return p, nil
+	}
+}
+
+// Convert rows with [n] goroutines. Conversion (type converters and field extensions) runs concurrently,
+// field extensions must be safe for concurrent use. Rows are added to the tree in order, row ids,
+// the order of defects and duplicate key detection are the same as with a single worker.
+func Workers(n int) Opt {
+	return func(p *parser) (*parser, error) {
+		if n < 1 {
+			return nil, errors.New("parser/options: workers must be > 0")
+		}
+		p.workers = n
+		return p, nil
+	}
+}
+
+// Match a trailer row where the first non empty cell starts with [prefix], case insensitive. Ex. "TOTAL"
+func Prefix(prefix string) TrailerMatch {
+	prefix = strings.ToLower(prefix)
+	return func(row []string) bool {
+		for _, v := range row {
+			if v = strings.TrimSpace(v); v != "" {
+				return strings.HasPrefix(strings.ToLower(v), prefix)
+			}
+		}
+		return false
+	}
+}
+
+// Check the record count declared in column [col] of the trailer row.
+// Digits are taken from the cell, ex. "Record count: 1,024"
+func CountAt(col int) TrailerCheck {
+	return func(row []string, count int) error {
+		if col < 0 || col >= len(row) {
+			return errors.New(fmt.Sprintf("parser/parser: trailer has no column %d", col))
+		}
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, row[col])
+		declared, err := strconv.Atoi(digits)
+		if err != nil {
+			return errors.New(fmt.Sprintf("parser/parser: trailer record count %q is not a number", row[col]))
+		}
+		if declared != count {
+			return errors.New(fmt.Sprintf("parser/parser: trailer declares %d records, %d parsed", declared, count))
+		}
+		return nil
+	}
+}
+
+// Merge a multi row header into a single row
+func mergeHeader(rows [][]string) []string {
+	width := 0
+	for _, r := range rows {
+		if len(r) > width {
+			width = len(r)
+		}
+	}
+	merged := make([]string, width)
+	for ri, r := range rows {
+		last := ""
+		for ci := 0; ci < width; ci++ {
+			v := ""
+			if ci < len(r) {
+				v = strings.TrimSpace(r[ci])
+			}
+			if v == "" && ri < len(rows)-1 {
+				v = last
+			}
+			last = v
+			if v == "" {
+				continue
+			}
+			if merged[ci] == "" {
+				merged[ci] = v
+			} else {
+				merged[ci] = merged[ci] + " " + v
+			}
+		}
+	}
+	return merged
+}
+
+// Is [row] a header for the schema, all required columns must be found
+func isHeader(row []string, def *types.Schema) bool {
+	found := make(map[string]bool)
+	for _, field := range row {
+		field = strings.TrimSpace(field)
+		for _, c := range def.Cols() {
+			if field == c.Name {
+				found[c.Name] = true
+				break
+			}
+			for _, a := range c.Aliases {
+				if field == a {
+					found[c.Name] = true
+					break
+				}
+			}
+		}
+	}
+	if len(found) == 0 {
+		return false
+	}
+	for _, c := range def.Cols() {
+		if c.Required && !found[c.Name] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,11 +1,13 @@
 package parser
 
 import (
-	"encoding/csv"
+	"context"
 	"fmt"
 	"io"
-	"log"
+	"math"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/loanpal-engineering/exttra/data"
@@ -21,44 +23,137 @@ type (
 		Parse() error
 	}
 	parser struct {
-		data      pkg.Composer
-		input     input.Input
+		data   pkg.Composer
+		input  input.Input
+		schema *types.Schema
+		// schema or option error, returned by Validate and Parse
+		err       error
 		headerIdx uint32
 		primary   []uint64
-		keys      map[uint64]map[string]uint8
+		// unique columns and schema keys, see [parser.constrain]
+		constraints []*constraint
+		provenance  input.Provenance
+		// typed values of the current row, only set when the input implements input.Native
+		native input.Native
+		// hidden source and line columns, only set when the input implements input.Provenance
+		origin []pkg.Composer
+		// header and trailer handling, see options
+		skip         uint32
+		detect       uint32
+		headerRows   uint32
+		skipBlank    bool
+		trailer      TrailerMatch
+		trailerCheck TrailerCheck
+		count        int
+		// number of fields expected in a record of a positional schema, zero when columns are bound by header
+		width int
+		// streaming, row r of the tree is row base + r of the body. Defects logged before [from] belong to previous batches
+		base uint32
+		from int
+		// number of goroutines converting rows, see [Workers]
+		workers int
+		// reading stops once the context is done, see [parser.ParseContext]
+		ctx context.Context
+	}
+	// A row read from the input with its location, see [parser.record]
+	record struct {
+		fields []string
+		// typed values of a native input
+		values []interface{}
+		// row of the body, and row of the tree
+		row  uint32
+		node uint32
+		line int
+		// name of the source, when the input implements input.Provenance
+		source string
+	}
+	// The nodes and defects of a converted record
+	converted struct {
+		record  *record
+		cells   []cell
+		defects []pkg.Defect
+		err     error
+	}
+	cell struct {
+		// position of the node in the row, and of the field in the record
+		index int
+		field int
+		col   pkg.Composer
+		n     pkg.Composer
+		// exclude the node from the output
+		hidden bool
+		// failing to add the node to the column is fatal
+		fatal bool
 	}
 )
 
+// Rows converted by each worker in a chunk
+const chunkRows = 64
+
 // Create a new parser object for the input provided.
-func NewParser(in *input.Input) *parser {
+// An invalid schema or option is returned by [Validate] and [Parse].
+// Optional properties:
+// 		Skip: discard leading records before the header
+// 		DetectHeader: find the header row by matching schema columns
+// 		HeaderRows: merge a header spanning several rows
+// 		SkipBlankRows: continue parsing past blank rows
+// 		Trailer: stop parsing at, and optionally validate, a trailer row
+// 		Workers: convert rows concurrently
+func NewParser(in *input.Input, opts ...Opt) *parser {
 	if in == nil {
 		return nil
 	}
 	i := new(parser)
 	i.input = *in
 	i.primary = make([]uint64, 0, 10)
-	i.keys = make(map[uint64]map[string]uint8)
+	i.headerRows = 1
+	i.workers = 1
+	i.ctx = context.Background()
+	if pv, ok := i.input.(input.Provenance); ok {
+		i.provenance = pv
+	}
+	if nv, ok := i.input.(input.Native); ok {
+		i.native = nv
+	}
+	if def, ok := i.input.GetSchema().(*types.Schema); !ok || def == nil {
+		i.err = pkg.NewSchemaException("", "input schema is not a *types.Schema")
+	} else if i.err = def.Err(); i.err == nil {
+		i.schema = def
+	}
+	for _, o := range opts {
+		ii, err := o(i)
+		if err != nil {
+			if i.err == nil {
+				i.err = err
+			}
+			continue
+		}
+		i = ii
+	}
 	return i
 }
-func (p *parser) readRow() []string {
-	reader := p.input.GetReader().(*csv.Reader)
+
+// Read the next row of the body.
+// A nil row is returned at the end of the body; the end of the input, the first blank row (unless blank rows are skipped),
+// or a trailer row. Records of an input.Native are never blank rows, a record with every value empty is a row of nulls.
+func (p *parser) readRow() ([]string, error) {
 	currentRow := &p.headerIdx
-	var (
-		err error = nil
-	)
-	row, err := reader.Read()
+read:
+	if err := p.ctx.Err(); err != nil {
+		return nil, err
+	}
+	row, err := p.input.Next()
 	if err != nil {
 		if err == io.EOF {
-			if len(p.primary) > 0 {
-				p.fillInDefects()
-			}
-			return nil
-		} else {
-			log.Fatal(err.Error())
+			return nil, nil
+		}
+		source := ""
+		if p.provenance != nil {
+			source = p.provenance.Source()
 		}
+		return nil, pkg.NewInputException(p.input.Line(), source, err)
 	}
-	*currentRow++
-	process := false
+	process := p.native != nil
 	for _, v := range row {
 		if len(v) > 0 {
 			process = true
@@ -66,12 +161,27 @@ func (p *parser) readRow() []string {
 		}
 	}
 	if !process || len(row) == 0 {
-		if len(p.primary) > 0 {
-			p.fillInDefects()
+		if p.skipBlank {
+			goto read
 		}
-		return nil
+		return nil, nil
+	}
+	if p.trailer != nil && p.trailer(row) {
+		if p.trailerCheck != nil {
+			return nil, p.trailerCheck(row, p.count)
+		}
+		return nil, nil
+	}
+	*currentRow++
+	p.count++
+	return row, nil
+}
+
+// The end of the body has been reached
+func (p *parser) end() {
+	if len(p.primary) > 0 {
+		p.fillInDefects()
 	}
-	return row
 }
 func (p *parser) colDef(colIdx uint64, defs []*types.ColumnDefinition) *types.ColumnDefinition {
 	i := 0
@@ -85,41 +195,51 @@ loop:
 	}
 	return nil
 }
-func (p *parser) parseRow(row []string) error {
-	currentRow := &p.headerIdx
-	colRow := make([]pkg.Composer, len(*p.data.Children()))
+
+// Convert a record to the nodes of a row.
+// Conversion does not change the tree, records are converted concurrently by the workers, see [Workers].
+func (p *parser) convertRow(r *record) *converted {
+	row := r.fields
+	cv := &converted{record: r}
 	offset := 0
+	if p.width > 0 && len(row) != p.width {
+		d := r.defect(-1)
+		d.Msg = fmt.Sprintf("parser/parser: record has %d fields, schema expects %d", len(row), p.width)
+		cv.defects = append(cv.defects, d)
+		for len(row) < p.width {
+			row = append(row, "")
+		}
+	}
 	for i, field := range row {
 		var (
 			id     uint64
 			n      pkg.Composer            = nil
 			col    pkg.Composer            = nil
-			err    error                   = nil
 			colDef *types.ColumnDefinition = nil
 		)
 		colIdx := uint64(i)
-		d := pkg.Defect{
-			Col: i,
-			Row: int(*currentRow),
-		}
+		d := r.defect(i)
 		colId := pkg.GenNodeId(uint32(colIdx), uint32(0))
 		if col = p.data.FindById(colId); col == nil {
 			offset++
 			continue
 		} else {
-			p.input.GetSchema().(*types.Schema).Cols()
-			colDef = p.colDef(colId, p.input.GetSchema().(*types.Schema).Cols())
+			colDef = p.colDef(colId, p.schema.Cols())
 			if colDef == nil {
-				return errors.New(fmt.Sprint("parser/Parse: schema column definition not found for index %l", colIdx))
+				cv.err = errors.New(fmt.Sprint("parser/Parse: schema column definition not found for index %l", colIdx))
+				return cv
 			}
 
-			id = pkg.GenNodeId(uint32(colIdx), *currentRow)
+			id = pkg.GenNodeId(uint32(colIdx), r.node)
 			nilNode, _ := data.NewNode(&id)
-			if item, en, err := colDef.Field.Convert(&field); err != nil {
+			if item, en, err := p.convert(r, i, colDef, &field); err != nil {
 				d.Msg = err.Error()
 				n = nilNode
 			} else if en != nil {
 				n, err = data.NewNode(&id)
+				if d.Rule, err = colDef.Field.Check(&field, nil); err != nil {
+					d.Msg = err.Error()
+				}
 			} else {
 				if colDef.Field.Extension != nil {
 					if item, err = colDef.Field.Extension(item); err != nil || item == nil {
@@ -127,16 +247,20 @@ func (p *parser) parseRow(row []string) error {
 							d.Msg = err.Error()
 						}
 						n = nilNode
-						if err = col.Add(n, true); err != nil {
-							d.Msg = err.Error()
-						}
-						colRow[i-offset] = n
+						cv.cells = append(cv.cells, cell{index: i - offset, field: i, col: col, n: n, hidden: true})
 						if d.Msg != "" {
-							pkg.LogDefect(d)
+							cv.defects = append(cv.defects, d)
 						}
 						continue
 					}
 				}
+				// a value breaking a rule of the field is hidden like a value that does not convert
+				if d.Rule, err = colDef.Field.Check(&field, item); err != nil {
+					d.Msg = err.Error()
+					cv.cells = append(cv.cells, cell{index: i - offset, field: i, col: col, n: nilNode, hidden: true})
+					cv.defects = append(cv.defects, d)
+					continue
+				}
 
 				switch colDef.Field.T {
 				case pkg.TIMESTAMP:
@@ -253,25 +377,166 @@ func (p *parser) parseRow(row []string) error {
 						d.Msg = "parser/parse: int8 was expected"
 						n = nilNode
 					}
+				case pkg.DECIMAL:
+					switch item.(type) {
+					case pkg.Decimal:
+						n, err = data.NewNode(&id, data.V(item.(pkg.Decimal)))
+					default:
+						d.Msg = "parser/parse: decimal was expected"
+						n = nilNode
+					}
+				case pkg.ENUM:
+					switch item.(type) {
+					case pkg.Category:
+						n, err = data.NewNode(&id, data.V(item.(pkg.Category)))
+					default:
+						d.Msg = "parser/parse: category was expected"
+						n = nilNode
+					}
 				default:
 					d.Msg = "parser/parse: type not defined by pkg.FieldType"
 					n = nilNode
 				}
 			}
 			if n == nil {
-				log.Fatal("parser/parser: nil node")
+				cv.err = pkg.NewConversionException(i, int(r.row), "parser/parser: nil node", nil)
+				return cv
 			}
 			if d.Msg != "" {
-				pkg.LogDefect(d)
+				cv.defects = append(cv.defects, d)
 			}
-			if err = col.Add(n, n.Value() == nil); err != nil {
-				d.Msg = err.Error()
-				pkg.FatalDefect(d)
+			cv.cells = append(cv.cells, cell{index: i - offset, field: i, col: col, n: n, hidden: n.Value() == nil, fatal: true})
+		}
+	}
+	return cv
+}
+
+// Add a converted record to the tree. Records are committed in the order they are read,
+// defects are logged and primary keys checked in row order whatever the number of workers.
+func (p *parser) commit(cv *converted) error {
+	if cv.err != nil {
+		return cv.err
+	}
+	for _, d := range cv.defects {
+		pkg.LogDefect(d)
+	}
+	colRow := make([]pkg.Composer, len(*p.data.Children()))
+	for _, c := range cv.cells {
+		if err := c.col.Add(c.n, c.hidden); err != nil {
+			d := cv.record.defect(c.field)
+			d.Msg = err.Error()
+			if c.fatal {
+				return pkg.FatalDefect(d)
 			}
-			colRow[i-offset] = n
+			pkg.LogDefect(d)
 		}
+		colRow[c.index] = c.n
+	}
+	if p.origin != nil {
+		if err := p.addOrigin(cv.record, colRow); err != nil {
+			return err
+		}
+	}
+	if err := p.linkRow(colRow); err != nil {
+		return err
+	}
+	if len(p.constraints) > 0 {
+		return p.keyed(cv.record)
+	}
+	return nil
+}
+
+// Convert the field at position [i] of the record.
+// The typed value of a native input is used as is when it is of the column's type.
+func (p *parser) convert(r *record, i int, colDef *types.ColumnDefinition, field *string) (interface{}, *string, error) {
+	if colDef.Field.T != pkg.STRING && colDef.Field.T != pkg.CUSTOM {
+		if i < len(r.values) && native(colDef.Field.T, r.values[i]) {
+			// a typed time follows the field's date/time policy as a converted one
+			if t, ok := r.values[i].(time.Time); ok {
+				return colDef.Field.ConvertTime(t), nil, nil
+			}
+			return r.values[i], nil, nil
+		}
+	}
+	return colDef.Field.Convert(field)
+}
+
+// Is [v] of the go type stored for field type [t]
+func native(t pkg.FieldType, v interface{}) bool {
+	switch v.(type) {
+	case time.Time:
+		return t == pkg.DATE || t == pkg.TIMESTAMP
+	case float32:
+		return t == pkg.FLOAT32
+	case float64:
+		return t == pkg.FLOAT || t == pkg.FLOAT64
+	case bool:
+		return t == pkg.BOOL
+	case int64:
+		return t == pkg.INT || t == pkg.INT64
+	case int32:
+		return t == pkg.INT32
+	case int16:
+		return t == pkg.INT16
+	case int8:
+		return t == pkg.INT8
+	case uint64:
+		return t == pkg.UINT || t == pkg.UINT64
+	case uint32:
+		return t == pkg.UINT32
+	case uint16:
+		return t == pkg.UINT16
+	case uint8:
+		return t == pkg.UINT8
+	default:
+		return false
+	}
+}
+
+// Capture the last row read with its location, the input may reuse the row once the next row is read
+func (p *parser) record(row []string) *record {
+	r := &record{
+		fields: append([]string(nil), row...),
+		row:    p.headerIdx,
+		node:   p.headerIdx - p.base,
+		line:   p.input.Line(),
+	}
+	if p.native != nil {
+		r.values = append([]interface{}(nil), p.native.Values()...)
+	}
+	if p.provenance != nil {
+		r.source = p.provenance.Source()
+	}
+	return r
+}
+
+// Create a defect for the cell at [col] of the record, located in the original source
+func (r *record) defect(col int) pkg.Defect {
+	return pkg.Defect{
+		Col:    col,
+		Row:    int(r.row),
+		Line:   r.line,
+		Source: r.source,
 	}
-	return p.linkRow(colRow)
+}
+
+// Add the source and line of the record to the hidden provenance columns.
+// Provenance columns are always the last nodes of the row.
+func (p *parser) addOrigin(r *record, colRow []pkg.Composer) error {
+	values := []interface{}{r.source, int64(r.line)}
+	for ii, col := range p.origin {
+		_, colIdx, _ := col.Id()
+		id := pkg.GenNodeId(colIdx, r.node)
+		n, err := data.NewNode(&id, data.V(values[ii]))
+		if err != nil {
+			return err
+		}
+		if err = col.Add(n, false); err != nil {
+			return err
+		}
+		colRow[len(colRow)-len(p.origin)+ii] = n
+	}
+	return nil
 }
 func (p *parser) linkRow(row []pkg.Composer) error {
 	var (
@@ -279,7 +544,7 @@ func (p *parser) linkRow(row []pkg.Composer) error {
 		err error = nil
 	)
 loop:
-	if i > len(row) {
+	if i > len(row) || len(row) < 2 {
 		return err
 	}
 	if i == 0 {
@@ -298,102 +563,164 @@ loop:
 		goto loop
 	}
 }
-func (p *parser) keyed(row []string, rowIdx *uint64) error {
-	for _, pi := range p.primary {
+// Parse the body of the file.
+// The root node of the parse tree is returned.
+// Use this node for writing to an output, or creating a new view of the data.
+// See [output] and [view]
+// A positional schema may be parsed without validation, see [types.Positional]
+func (p *parser) Parse() (pkg.Composer, error) {
+	return p.ParseContext(context.Background())
+}
+
+// Parse the body of the file, see [parser.Parse].
+// Reading stops once [ctx] is done, the conversion goroutines are released and the context's error is returned.
+func (p *parser) ParseContext(ctx context.Context) (pkg.Composer, error) {
+	p.ctx = ctx
+	if err := p.ready(); err != nil {
+		return nil, err
+	}
+	if _, _, err := p.parse(0); err != nil {
+		return nil, err
+	}
+	return p.data, nil
+}
+
+// Parse up to [limit] rows of the body, all rows when [limit] is 0.
+// The number of rows parsed is returned, and whether the end of the body has been reached.
+// Rows are read in chunks, the rows of a chunk are converted by the workers and committed in order.
+func (p *parser) parse(limit uint32) (uint32, bool, error) {
+	parsed := uint32(0)
+	for limit == 0 || parsed < limit {
+		size := uint32(1)
+		if p.workers > 1 {
+			size = uint32(p.workers) * chunkRows
+		}
+		if limit > 0 && limit-parsed < size {
+			size = limit - parsed
+		}
 		var (
-			col    pkg.Composer = nil
-			colIdx uint32       = 0
+			records = make([]*record, 0, size)
+			end     = false
+			readErr error
 		)
-		col = p.data.FindById(pi)
-		if pkg.IsNil(col) {
-			return errors.New("parser/parser: primary key column not found")
-		}
-		_, colIdx, _ = col.Id()
-		if _, exists := p.keys[uint64(colIdx)]; !exists {
-			colKeyMap := make(map[string]uint8)
-			p.keys[uint64(colIdx)] = colKeyMap
-		}
-		candidate := strings.TrimSpace((row)[colIdx])
-		_, exists := p.keys[uint64(colIdx)][candidate]
-		if exists {
-			pkg.LogDefect(pkg.Defect{
-				Msg: fmt.Sprintf("Duplicate id [%s]", candidate),
-				Row: int(*rowIdx),
-				Col: int(colIdx),
-			})
-			p.keys[uint64(colIdx)][candidate]++
-			col.(pkg.Editor).Toggle(pkg.GenNodeId(colIdx, uint32(*rowIdx)), true)
-		} else {
-			p.keys[uint64(colIdx)][candidate] = 0
+		for uint32(len(records)) < size {
+			row, err := p.readRow()
+			if err != nil {
+				readErr = err
+				break
+			} else if row == nil {
+				end = true
+				break
+			}
+			records = append(records, p.record(row))
+		}
+		// the records read before a read or trailer error are committed before the error is returned
+		for _, cv := range p.convertRows(records) {
+			if err := p.commit(cv); err != nil {
+				return parsed, true, err
+			}
+		}
+		parsed += uint32(len(records))
+		if readErr != nil {
+			return parsed, true, readErr
+		}
+		if end {
+			p.end()
+			return parsed, true, nil
 		}
 	}
-	return nil
+	return parsed, false, nil
 }
 
-// Parse the body of the file.
-// The root node of the parse tree is returned.
-// Use this node for writing to an output, or creating a new view of the data.
-// See [output] and [view]
-func (p *parser) Parse() (pkg.Composer, error) {
-parse:
+// Convert records with the worker pool, converted records are returned in the order of [records]
+func (p *parser) convertRows(records []*record) []*converted {
+	out := make([]*converted, len(records))
+	if p.workers < 2 || len(records) < 2 {
+		for ii, r := range records {
+			out[ii] = p.convertRow(r)
+		}
+		return out
+	}
 	var (
-		err error = nil
+		wg   sync.WaitGroup
+		jobs = make(chan int)
 	)
-	if row := p.readRow(); row != nil {
-		if err = p.parseRow(row); err != nil {
-			return nil, err
-		}
-		if len(p.primary) > 0 {
-			k := uint64(p.headerIdx)
-			if err = p.keyed(row, &k); err != nil {
-				return nil, err
+	for w := 0; w < p.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ii := range jobs {
+				out[ii] = p.convertRow(records[ii])
 			}
-		}
-	} else {
-		return p.data, nil
+		}()
+	}
+	for ii := range records {
+		jobs <- ii
 	}
-	err = nil
-	goto parse
+	close(jobs)
+	wg.Wait()
+	return out
+}
+
+// Check the columns are bound, a positional schema is bound on the first call
+func (p *parser) ready() error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.data != nil {
+		return nil
+	}
+	if !p.schema.IsPositional() {
+		return errors.New("parser/parser: Validate must be called before Parse")
+	}
+	return p.bind(p.schema)
 }
 
 // Validate the input's schema against the file.
-// If validation fails an error is returned
+// If validation fails an error is returned.
+// A positional schema has no header, the columns are bound by position and [index] is ignored.
 func (p *parser) Validate(index *uint32) error {
+	return p.ValidateContext(context.Background(), index)
+}
+
+// Validate the input's schema against the file, see [parser.Validate].
+// Reading stops once [ctx] is done and the context's error is returned.
+func (p *parser) ValidateContext(ctx context.Context, index *uint32) error {
 	var (
-		headers    []string
-		dupes             = make([]string, 0)
-		currentRow        = &p.headerIdx
-		reader            = p.input.GetReader().(*csv.Reader)
-		def               = p.input.GetSchema().(*types.Schema)
-		hi         uint32 = 0
-		colRow            = make([]pkg.Composer, 0)
+		headers []string
+		dupes          = make([]string, 0)
+		def            = p.schema
+		hi      uint32 = 0
+		colRow         = make([]pkg.Composer, 0)
+		found          = make(map[string]bool)
 	)
 
+	if p.err != nil {
+		return p.err
+	}
+	p.ctx = ctx
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if def.IsPositional() {
+		return p.bind(def)
+	}
 	if index != nil {
 		hi = *index
 	} else {
 		hi = 0
 	}
 
-	for {
-		row, err := reader.Read()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-		}
-		if *currentRow < hi {
-			*currentRow++
-			continue
+	headers, err := p.header(hi, def)
+	if err != nil {
+		if err == io.EOF {
+			return errors.New("parser/parser: header row not found")
 		}
-		headers = row
-		break
+		return err
 	}
 	// hash headers for dupes check
 	hHeaders := map[string]int8{}
 	p.data, _ = data.NewNode(nil) // root node
-	// required headers count, used to check all required fields are found
-	reqHeadCount := 0
 	// iterate over header row
 	// if header is part of schema signature (def) create a new node
 
@@ -433,9 +760,7 @@ func (p *parser) Validate(index *uint32) error {
 		if col == nil {
 			continue
 		}
-		if col.Required {
-			reqHeadCount++
-		}
+		found[col.Name] = true
 		if col.Required && dupe {
 			dupes = append(dupes, field)
 		} else if dupe && !col.Required {
@@ -443,46 +768,181 @@ func (p *parser) Validate(index *uint32) error {
 			// The parser will use the first instance of the column found
 			continue
 		}
-		id := pkg.GenNodeId(uint32(i), 0)
-		if col.Unique {
-			p.primary = append(p.primary, id)
-		}
-		col.Index = id
-		opts := []data.Opt{
-			data.Nullable(col.Field.Nil),
-			data.Name(field),
-			data.Type(&col.Field.T),
-		}
-		if def.Indexed(field) {
-			opts = append(opts, data.Index(true))
-		}
-		if n, err := data.NewNode(&id, opts...); err != nil {
-			log.Fatalf(err.Error())
-		} else if err = p.data.Add(n, false); err != nil {
-			log.Fatalf(err.Error())
+		if n, err := p.column(uint32(i), col, def); err != nil {
+			return err
 		} else {
 			colRow = append(colRow, n)
 		}
-
 	}
 	if len(dupes) > 0 {
 		return errors.New(fmt.Sprintf("parser/parser: duplicate column(s) %s found= ", strings.Join(dupes, ",")))
 	}
-	cc := 0
-	for _, l := range *p.data.Children() {
-		if l != nil {
-			cc++
+	if p.provenance != nil {
+		if err = p.originColumns(&colRow); err != nil {
+			return err
 		}
 	}
-	if reqHeadCount > cc {
-		// todo: missing column send back to sender
-		pkg.FatalDefect(pkg.Defect{
-			Msg: "Missing required column(s)",
-		})
+	missing := make([]string, 0)
+	for _, c := range def.Cols() {
+		if c.Required && !found[c.Name] {
+			missing = append(missing, c.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return pkg.NewColumnNotFoundException(missing, pkg.FatalDefect(pkg.Defect{
+			Row: -1,
+			Col: -1,
+			Msg: fmt.Sprintf("Missing required column(s) %s", strings.Join(missing, ",")),
+		}))
+	}
+	if err = p.constrain(def); err != nil {
+		return err
 	}
 	return p.linkRow(colRow)
 }
 
+// Bind the columns of a positional schema, no header is read.
+// Columns are named from the schema, records discarded by [Skip] are read here.
+func (p *parser) bind(def *types.Schema) error {
+	var (
+		cols   = def.Cols()
+		bound  = make([]*types.ColumnDefinition, 0, len(cols))
+		taken  = make(map[int]string)
+		colRow = make([]pkg.Composer, 0, len(cols))
+	)
+	p.data, _ = data.NewNode(nil) // root node
+	p.width = 0
+	for _, c := range cols {
+		pos := def.PositionOf(c)
+		if other, exists := taken[pos]; exists {
+			return errors.New(fmt.Sprintf("parser/parser: columns %s and %s are bound to position %d", other, c.Name, pos))
+		}
+		taken[pos] = c.Name
+		bound = append(bound, c)
+		if pos+1 > p.width {
+			p.width = pos + 1
+		}
+	}
+	// nodes are linked in record order
+	sort.SliceStable(bound, func(a, b int) bool {
+		return def.PositionOf(bound[a]) < def.PositionOf(bound[b])
+	})
+	for _, c := range bound {
+		n, err := p.column(uint32(def.PositionOf(c)), c, def)
+		if err != nil {
+			return err
+		}
+		colRow = append(colRow, n)
+	}
+	for ii := uint32(0); ii < p.skip; ii++ {
+		if _, err := p.input.Next(); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+	}
+	p.headerIdx = p.skip
+	if p.provenance != nil {
+		if err := p.originColumns(&colRow); err != nil {
+			return err
+		}
+	}
+	if err := p.constrain(def); err != nil {
+		return err
+	}
+	return p.linkRow(colRow)
+}
+
+// Add the column node for the schema column [col] found at position [i] of the record
+func (p *parser) column(i uint32, col *types.ColumnDefinition, def *types.Schema) (pkg.Composer, error) {
+	id := pkg.GenNodeId(i, 0)
+	if col.Unique {
+		p.primary = append(p.primary, id)
+	}
+	col.Index = id
+	opts := []data.Opt{
+		data.Nullable(col.Field.Nil),
+		data.Name(col.Name),
+		data.Type(&col.Field.T),
+	}
+	if def.Indexed(col.Name) {
+		opts = append(opts, data.Index(true))
+	}
+	n, err := data.NewNode(&id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err = p.data.Add(n, false); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Read the header row at [index], honouring the skip, detection and multi row header options.
+// The row counter is left on the last header row.
+func (p *parser) header(index uint32, def *types.Schema) ([]string, error) {
+	if p.detect > 0 {
+		index = 0
+	}
+	index += p.skip
+	row, err := p.input.Header(index)
+	if err != nil {
+		return nil, err
+	}
+	if p.detect > 0 {
+		for scanned := uint32(1); !isHeader(row, def); scanned++ {
+			if err = p.ctx.Err(); err != nil {
+				return nil, err
+			}
+			if scanned >= p.detect {
+				return nil, errors.New(fmt.Sprintf("parser/parser: header row not found in %d rows", p.detect))
+			}
+			if row, err = p.input.Next(); err != nil {
+				return nil, err
+			}
+			index++
+		}
+	}
+	if p.headerRows > 1 {
+		rows := [][]string{append([]string(nil), row...)}
+		for ii := uint32(1); ii < p.headerRows; ii++ {
+			if row, err = p.input.Next(); err != nil {
+				return nil, err
+			}
+			rows = append(rows, append([]string(nil), row...))
+			index++
+		}
+		row = mergeHeader(rows)
+	}
+	p.headerIdx = index
+	return row, nil
+}
+
+// Add the hidden source and line columns to the root node.
+// The columns are excluded from the output unless selected by a view.
+func (p *parser) originColumns(colRow *[]pkg.Composer) error {
+	var (
+		nullable = &pkg.Nullable{Allowed: true}
+		names    = []string{pkg.SourceColumn, pkg.LineColumn}
+		ts       = []pkg.FieldType{pkg.STRING, pkg.INT64}
+	)
+	p.origin = make([]pkg.Composer, 0, len(names))
+	for ii, name := range names {
+		id := pkg.GenNodeId(math.MaxUint32-uint32(len(names)-1-ii), 0)
+		n, err := data.NewNode(&id, data.Name(name), data.Type(&ts[ii]), data.Nullable(nullable))
+		if err != nil {
+			return err
+		}
+		if err = p.data.Add(n, true); err != nil {
+			return err
+		}
+		p.origin = append(p.origin, n)
+		*colRow = append(*colRow, n)
+	}
+	return nil
+}
+
 // If a primary key is defined on the input,
 // iterate pkg. column and row to get the key
 // to each defect
@@ -491,19 +951,26 @@ func (p *parser) fillInDefects() {
 	d := pkg.NewDC()
 	defs := d.Coll()
 	if len(p.primary) > 0 {
+	headers:
 		for _, colIdx := range p.primary {
 			col := p.data.FindById(colIdx)
 			if pkg.IsNil(col) {
 				continue
 			}
+			for _, h := range d.(*pkg.Defects).Headers {
+				if h == col.Name() {
+					continue headers
+				}
+			}
 			d.(*pkg.Defects).Headers = append(d.(*pkg.Defects).Headers, col.Name())
 		}
-		for i, v := range *defs {
+		for i := p.from; i < len(*defs); i++ {
+			v := (*defs)[i]
 			if v.Keys == nil {
 				(*defs)[i].Keys = make(map[string]string)
 			}
 			rowIdx := v.Row
-			if rowIdx == -1 {
+			if rowIdx == -1 || rowIdx <= int(p.base) {
 				continue
 			}
 
@@ -513,12 +980,13 @@ func (p *parser) fillInDefects() {
 				if pkg.IsNil(col) {
 					continue
 				}
-				id := pkg.GenNodeId(colIdx, uint32(rowIdx))
+				id := pkg.GenNodeId(colIdx, uint32(rowIdx)-p.base)
 				row := col.FindById(id)
 				if row == nil {
 					continue
 				}
-				(*defs)[i].Keys[col.Name()] = row.Value().(string)
+				// keys are not only string columns, ex. an inferred unique INT64 column
+				(*defs)[i].Keys[col.Name()] = *types.SimpleToString(row.Value())
 			}
 		}
 	}
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/loanpal-engineering/exttra/data"
+	"github.com/loanpal-engineering/exttra/pkg"
+)
+
+type (
+	// Batch is a run of consecutive rows of the body parsed into a tree of its own.
+	// The tree has the columns of the parse tree, use it as the root node of a view or an output.
+	Batch struct {
+		Root pkg.Composer
+		// Row r of the batch tree is row Offset + r of the body
+		Offset uint32
+	}
+	// Handle a batch, a returned error stops the stream
+	BatchHandler func(Batch) error
+)
+
+// Parse the body in batches of [size] rows, each batch is handed to [fn] and released once [fn] returns.
+// Memory is bounded by the batch size rather than the size of the input.
+// Duplicate primary keys are detected across batches, a duplicate is excluded from the batch it is found in.
+// Validate must be called first, unless the schema is positional.
+func (p *parser) Stream(size uint32, fn BatchHandler) error {
+	return p.StreamContext(context.Background(), size, fn)
+}
+
+// Parse the body in batches of [size] rows, see [parser.Stream].
+// The stream stops once [ctx] is done and the context's error is returned.
+func (p *parser) StreamContext(ctx context.Context, size uint32, fn BatchHandler) error {
+	if size == 0 {
+		return errors.New("parser/stream: batch size must be > 0")
+	}
+	if fn == nil {
+		return errors.New("parser/stream: a batch handler is required")
+	}
+	p.ctx = ctx
+	if err := p.ready(); err != nil {
+		return err
+	}
+	for {
+		rows, end, err := p.parse(size)
+		if err != nil {
+			return err
+		}
+		if rows > 0 {
+			// at the end of the body defects have been keyed by parse
+			if !end && len(p.primary) > 0 {
+				p.fillInDefects()
+			}
+			if err = fn(Batch{Root: p.data, Offset: p.base}); err != nil {
+				return err
+			}
+		}
+		if end {
+			return nil
+		}
+		if err = p.reset(); err != nil {
+			return err
+		}
+	}
+}
+
+// Parse the body in batches of [size] rows, see [Stream].
+// Batches are sent in order, the batch channel is closed at the end of the body.
+// The error channel receives the error stopping the stream, if any, and is closed once the stream ends.
+func (p *parser) Batches(size uint32) (<-chan Batch, <-chan error) {
+	return p.BatchesContext(context.Background(), size)
+}
+
+// Parse the body in batches of [size] rows, see [parser.Batches].
+// Once [ctx] is done the stream stops, the goroutine is released without a reader for the pending batch,
+// and the context's error is sent on the error channel.
+func (p *parser) BatchesContext(ctx context.Context, size uint32) (<-chan Batch, <-chan error) {
+	var (
+		batches = make(chan Batch)
+		errs    = make(chan error, 1)
+	)
+	go func() {
+		defer close(errs)
+		defer close(batches)
+		if err := p.StreamContext(ctx, size, func(b Batch) error {
+			select {
+			case batches <- b:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}); err != nil {
+			errs <- err
+		}
+	}()
+	return batches, errs
+}
+
+// Replace the tree with an empty tree of the same columns, the next row is row 1 of the new tree
+func (p *parser) reset() error {
+	var (
+		def    = p.schema
+		old    = p.data
+		ids    = make([]uint64, 0, len(*old.Children()))
+		colRow = make([]pkg.Composer, 0, len(*old.Children()))
+		origin = make([]pkg.Composer, 0, len(p.origin))
+	)
+	for id := range *old.Children() {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+	p.data, _ = data.NewNode(nil) // root node
+	for _, id := range ids {
+		var (
+			id       = id
+			col      = (*old.Children())[id]
+			t        = col.T()
+			nullable = col.Nullable()
+		)
+		opts := []data.Opt{
+			data.Nullable(&nullable),
+			data.Name(col.Name()),
+			data.Type(&t),
+		}
+		if def.Indexed(col.Name()) {
+			opts = append(opts, data.Index(true))
+		}
+		n, err := data.NewNode(&id, opts...)
+		if err != nil {
+			return err
+		}
+		if err = p.data.Add(n, old.Null()[id]); err != nil {
+			return err
+		}
+		for _, o := range p.origin {
+			if o == col {
+				origin = append(origin, n)
+			}
+		}
+		colRow = append(colRow, n)
+	}
+	if p.origin != nil {
+		p.origin = origin
+	}
+	p.base = p.headerIdx
+	p.from = pkg.NewDC().Count()
+	return p.linkRow(colRow)
+}
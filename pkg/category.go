@@ -0,0 +1,32 @@
+package pkg
+
+type (
+	// The allowed values of an ENUM field, shared by the categories of the field, see types.Enum
+	Categories struct {
+		Values []string
+	}
+	// The value of an ENUM field, the index of the value in the field's categories.
+	// A category is stored as a small integer, its value is shared by every row.
+	Category struct {
+		Code uint16
+		Of   *Categories
+	}
+)
+
+// Get the category of [value], false when [value] is not one of the categories
+func (c *Categories) Category(value string) (Category, bool) {
+	for ii, v := range c.Values {
+		if v == value {
+			return Category{Code: uint16(ii), Of: c}, true
+		}
+	}
+	return Category{}, false
+}
+
+// Get the value of the category
+func (c Category) String() string {
+	if c.Of == nil || int(c.Code) >= len(c.Of.Values) {
+		return ""
+	}
+	return c.Of.Values[c.Code]
+}
@@ -30,10 +30,21 @@ const (
 	DATE
 	CUSTOM
 	BOOL
+	// a fixed point number, see [Decimal]
+	DECIMAL
+	// one of a closed set of values, see [Category]
+	ENUM
 	NULL
 	UNKNOWN
 )
 
+// Names of the hidden columns holding the provenance (source name and line) of each row.
+// These columns are only added to the tree when the input reads several sources, see input.Concat
+const (
+	SourceColumn = "_source"
+	LineColumn   = "_line"
+)
+
 func (dt FieldType) String() string {
 	return [...]string{
 		"INT8",
@@ -54,6 +65,8 @@ func (dt FieldType) String() string {
 		"DATE",
 		"CUSTOM",
 		"BOOL",
+		"DECIMAL",
+		"ENUM",
 		"NULL",
 		"UNKNOWN",
 	}[dt]
@@ -74,6 +74,9 @@ type (
 		// If this seem unintuitive or backwards, recall this method is manipulating a nodes NILmap; a mapping of all child
 		// nodes which are nil (non-viewable) to an [Out] instance
 		Toggle(uint64, bool)
+		// Exclude a row from every version of the tree, whatever the nullability of its columns.
+		// Rows are excluded while parsing, ex. the duplicates of a key, see [Excludes]
+		Exclude(row uint32)
 		// Build an aggregated view of all excluded rows
 		Excludes() []bool
 		// Reset the tree to the initial visibility construction. To see how visibility is created during construction
@@ -92,6 +95,7 @@ type (
 		Headers       []string
 		coll          []*Defect
 		enabled       bool
+		exit          bool
 	}
 	Opt    func(*Defects) (*Defects, error)
 	Defect struct {
@@ -99,6 +103,11 @@ type (
 		Col  int
 		Keys map[string]string
 		Msg  string
+		// Rule is the name of the field rule broken by the value, see types.NotBlank
+		Rule string
+		// Source and Line locate the defect in the original source, see input.Provenance
+		Source string
+		Line   int
 	}
 )
 
@@ -0,0 +1,163 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// A fixed point decimal, the value of the decimal is Unscaled * 10^-Scale.
+	// The value of a DECIMAL field, see types.DecimalConverter
+	Decimal struct {
+		Unscaled int64
+		Scale    uint8
+	}
+)
+
+// The largest number of digits of a decimal
+const MaxPrecision = 18
+
+// Parse a plain decimal number, ex. -1234.5, to a decimal of [scale] digits after the point.
+// Digits beyond the scale are rounded half away from zero.
+func ParseDecimal(s string, scale uint8) (Decimal, error) {
+	if scale > MaxPrecision {
+		return Decimal{}, errors.New(fmt.Sprintf("pkg/decimal: scale %d exceeds %d", scale, MaxPrecision))
+	}
+	var (
+		neg    bool
+		in     = strings.TrimSpace(s)
+		digits string
+		frac   string
+	)
+	if strings.HasPrefix(in, "-") || strings.HasPrefix(in, "+") {
+		neg = in[0] == '-'
+		in = in[1:]
+	}
+	if point := strings.IndexByte(in, '.'); point >= 0 {
+		digits, frac = in[:point], in[point+1:]
+	} else {
+		digits = in
+	}
+	if digits == "" && frac == "" || !isDigits(digits) || !isDigits(frac) {
+		return Decimal{}, errors.New(fmt.Sprintf("pkg/decimal: can not parse \"%s\" to a decimal", s))
+	}
+	var round bool
+	if len(frac) > int(scale) {
+		round = frac[scale] >= '5'
+		frac = frac[:scale]
+	}
+	frac += strings.Repeat("0", int(scale)-len(frac))
+	digits = strings.TrimLeft(digits+frac, "0")
+	if digits == "" {
+		digits = "0"
+	}
+	unscaled, err := strconv.ParseInt(digits, 10, 64)
+	if err == nil && round {
+		unscaled++
+	}
+	// the digits are counted once rounded, rounding may carry into a further digit, ex. 999.5
+	if err != nil || len(strconv.FormatInt(unscaled, 10)) > MaxPrecision {
+		return Decimal{}, errors.New(fmt.Sprintf("pkg/decimal: \"%s\" exceeds %d digits", s, MaxPrecision))
+	}
+	if neg {
+		unscaled = -unscaled
+	}
+	return Decimal{Unscaled: unscaled, Scale: scale}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the number of digits of the decimal
+func (d Decimal) Precision() int {
+	digits := len(strconv.FormatUint(abs(d.Unscaled), 10))
+	if digits < int(d.Scale) {
+		return int(d.Scale)
+	}
+	return digits
+}
+
+func abs(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
+
+// Format the decimal with all the digits of its scale, ex. -1234.50
+func (d Decimal) String() string {
+	s := strconv.FormatUint(abs(d.Unscaled), 10)
+	if d.Scale > 0 {
+		if pad := int(d.Scale) + 1 - len(s); pad > 0 {
+			s = strings.Repeat("0", pad) + s
+		}
+		s = s[:len(s)-int(d.Scale)] + "." + s[len(s)-int(d.Scale):]
+	}
+	if d.Unscaled < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+// Get the closest float of the decimal
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Compare two decimals of any scale, the result is -1, 0 or +1 as [d] is less than, equal to or greater than [o]
+func (d Decimal) Cmp(o Decimal) int {
+	if d.Scale == o.Scale {
+		switch {
+		case d.Unscaled < o.Unscaled:
+			return -1
+		case d.Unscaled > o.Unscaled:
+			return 1
+		default:
+			return 0
+		}
+	}
+	l, r := d.big(), o.big()
+	if d.Scale < o.Scale {
+		l.Mul(l, pow10(o.Scale-d.Scale))
+	} else {
+		r.Mul(r, pow10(d.Scale-o.Scale))
+	}
+	return l.Cmp(r)
+}
+
+// Add two decimals, the sum has the larger scale of the two
+func (d Decimal) Add(o Decimal) (Decimal, error) {
+	l, r := d.big(), o.big()
+	scale := d.Scale
+	if d.Scale < o.Scale {
+		l.Mul(l, pow10(o.Scale-d.Scale))
+		scale = o.Scale
+	} else {
+		r.Mul(r, pow10(d.Scale-o.Scale))
+	}
+	sum := l.Add(l, r)
+	if !sum.IsInt64() || sum.Int64() == math.MinInt64 {
+		return Decimal{}, errors.New("pkg/decimal: sum overflows a decimal")
+	}
+	return Decimal{Unscaled: sum.Int64(), Scale: scale}, nil
+}
+
+func (d Decimal) big() *big.Int {
+	return big.NewInt(d.Unscaled)
+}
+
+func pow10(n uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -42,6 +42,11 @@ func (d *Defects) Count() int {
 	return len(d.coll)
 }
 
+// Exit the process on a FatalDefect, by default a fatal defect is returned as an error.
+func (d *Defects) ExitOnFatal(b bool) {
+	instance.exit = b
+}
+
 // Interrupt a FatalDefect to run the provided function
 // with the global collection of defects before the
 // process exits
@@ -66,6 +71,30 @@ func (d *Defects) Report(originalOffset int) [][]string {
 			d.Headers = append(d.Headers, k)
 		}
 	}
+	provenance, rules := false, false
+	for _, v := range d.coll {
+		provenance = provenance || v.Source != ""
+		rules = rules || v.Rule != ""
+	}
+	extra := make([]string, 0, 3)
+	if rules {
+		extra = append(extra, "Rule")
+	}
+	if provenance {
+		extra = append(extra, "Source", "Line")
+	}
+	for _, k := range extra {
+		found := false
+		for _, v := range d.Headers {
+			if k == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			d.Headers = append(d.Headers, k)
+		}
+	}
 	d.Headers = d.Headers[:len(d.Headers)]
 	rows = append(rows, d.Headers)
 	for _, v := range d.coll {
@@ -88,6 +117,17 @@ func (d *Defects) Report(originalOffset int) [][]string {
 				}
 			}
 		}
+		if provenance || rules {
+			for ii, j := range rows[0] {
+				if j == "Rule" {
+					row[ii] = v.Rule
+				} else if j == "Source" {
+					row[ii] = v.Source
+				} else if j == "Line" && v.Line > 0 {
+					row[ii] = strconv.Itoa(v.Line)
+				}
+			}
+		}
 		rows = append(rows, row)
 	}
 	return rows
@@ -113,23 +153,25 @@ func LogDefect(d Defect) {
 	}
 }
 
-// A fatal defect is fatal! The process WILL exit.
-// The defect will be added to the global defector object
-// To capture collected defects before the process exits
-// set the FatalExitInterrupt
-func FatalDefect(d Defect) {
+// A fatal defect stops the job. The defect is added to the global defector object
+// and returned as an error, the caller must stop and return the error.
+// The process exits only when enabled with [Defects.ExitOnFatal],
+// to capture collected defects before the process exits set the ExitInterrupt
+func FatalDefect(d Defect) error {
 	if d.Keys == nil {
 		d.Keys = map[string]string{}
 	}
 	defs := NewDC().(*Defects)
 	if defs.enabled {
 		defs.coll = append(defs.coll, &d)
+	} else {
+		log.Printf("defect: %s", d.Msg)
+	}
+	if defs.exit {
 		if defs.exitInterrupt != nil {
 			defs.exitInterrupt(defs.coll)
 		}
 		os.Exit(1)
-	} else {
-		log.Fatalf("defect: %s", d.Msg)
 	}
-
+	return &d
 }
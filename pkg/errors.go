@@ -1,122 +1,127 @@
 package pkg
-//
-// import (
-// 	"errors"
-// 	"fmt"
-//
-// 	"github.com/loanpal-engineering/exttra/types"
-// )
-//
-// type (
-// 	NullFieldException struct {
-// 		field   Composer
-// 		message string
-// 	}
-// 	ColumnNotFoundException struct {
-// 		columnIdx uint32
-// 		message   string
-// 	}
-// 	ExpressionException struct {
-// 		message string
-// 		lhs     Composer
-// 		rhs     Composer
-// 	}
-// 	ConversionException struct {
-// 		message string
-// 		field   Composer
-// 		stage   types.Prop
-// 	}
-// )
-//
-// // Create a new NullFieldException
-// // An error message can be provided with details of the null field
-// func NewNullFieldException(field Composer) *NullFieldException {
-// 	_, col, row := field.Id()
-// 	// Get the column node
-// 	parent := field.Parent()
-// 	for {
-// 		if IsNil(parent.Parent()) {
-// 			break
-// 		} else {
-// 			parent = parent.Parent()
-// 		}
-// 	}
-// 	colName := parent.Name()
-// 	return &NullFieldException{
-// 		field:   field,
-// 		message: fmt.Sprintf("errors/NullFieldException: %s col=%d row=%d", colName, col, row),
-// 	}
-// }
-// func (e *NullFieldException) Unwrap() error {
-// 	return errors.New(e.message)
-// }
-// func (e *NullFieldException) Error() string {
-// 	return e.message
-// }
-//
-// // Create a new ColumnNotFoundException
-// func NewColumnNotFoundException(col uint32) *ColumnNotFoundException {
-// 	return &ColumnNotFoundException{
-// 		columnIdx: col,
-// 		message:   fmt.Sprintf("errors/ColumnNotFoundException: column %d not found", col),
-// 	}
-// }
-// func (e *ColumnNotFoundException) Error() string {
-// 	return e.message
-// }
-// func (e *ColumnNotFoundException) Unwrap() error {
-// 	return errors.New(e.message)
-// }
-// func NewExpressionException(lhs, rhs Composer, msg string) *ExpressionException {
-// 	_, lCol, lRow := lhs.Id()
-// 	_, rCol, rRow := rhs.Id()
-// 	lValue := lhs.Value()
-// 	rValue := rhs.Value()
-// 	return &ExpressionException{
-// 		message: fmt.Sprintf("errors/ExpressionException: lhs col=%d row=%d value=%v, rhs col=%d row=%d value=%v, %s",
-// 			lCol,
-// 			lRow,
-// 			lValue,
-// 			rCol,
-// 			rRow,
-// 			rValue,
-// 			msg),
-// 		lhs: lhs,
-// 		rhs: rhs,
-// 	}
-// }
-// func (e *ExpressionException) Error() string {
-// 	return e.message
-// }
-// func (e *ExpressionException) Unwrap() error {
-// 	return errors.New(e.message)
-// }
-// func NewConversionException(stage types.Prop, field Composer, msg string) *ConversionException {
-// 	var (
-// 		stageString string
-// 	)
-// 	switch stage {
-// 	case types.ToString:
-// 		stageString = "toString"
-// 	case types.Convert:
-// 		stageString = "convert"
-// 	case types.Extension:
-// 		stageString = "extension"
-// 	}
-// 	_, col, row := field.Id()
-// 	return &ConversionException{
-// 		message: fmt.Sprintf("errors/ConversionException: field col=%d row=%d on %s failure %s",
-// 			col,
-// 			row,
-// 			stageString,
-// 			msg),
-// 		field: field,
-// 		stage: stage,
-// 	}
-// }
-// func (e *ConversionException) Error() string {
-// 	return e.message
-// }
-// func (e *ConversionException) Unwrap() error {
-// 	return errors.New(e.message)
-// }
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Errors returned by exttra in place of exiting the process.
+// Test for a kind of failure with errors.As, the cause of a failure (if any) is available with errors.Unwrap.
+// A fatal defect is returned as a *Defect, see [FatalDefect].
+type (
+	// The schema definition is invalid, ex. an option references a column that is not defined
+	SchemaException struct {
+		Column  string
+		message string
+	}
+	// Columns required by the schema are not found in the input
+	ColumnNotFoundException struct {
+		Columns []string
+		message string
+		cause   error
+	}
+	// The input can not be read, [Line] and [Source] locate the failure when known
+	InputException struct {
+		Line    int
+		Source  string
+		message string
+		cause   error
+	}
+	// A value could not be converted, or the node holding it could not be created
+	ConversionException struct {
+		Col     int
+		Row     int
+		message string
+		cause   error
+	}
+	// The tree could not be written to an output
+	OutputException struct {
+		message string
+		cause   error
+	}
+)
+
+// Create a new SchemaException for [column]
+func NewSchemaException(column, msg string) *SchemaException {
+	return &SchemaException{
+		Column:  column,
+		message: fmt.Sprintf("errors/SchemaException: column %s %s", column, msg),
+	}
+}
+func (e *SchemaException) Error() string {
+	return e.message
+}
+
+// Create a new ColumnNotFoundException for the missing [columns]
+func NewColumnNotFoundException(columns []string, cause error) *ColumnNotFoundException {
+	return &ColumnNotFoundException{
+		Columns: columns,
+		message: fmt.Sprintf("errors/ColumnNotFoundException: missing required column(s) %s", strings.Join(columns, ",")),
+		cause:   cause,
+	}
+}
+func (e *ColumnNotFoundException) Error() string {
+	return e.message
+}
+func (e *ColumnNotFoundException) Unwrap() error {
+	return e.cause
+}
+
+// Create a new InputException, [line] is 0 and [source] empty when the location is unknown
+func NewInputException(line int, source string, cause error) *InputException {
+	msg := "errors/InputException:"
+	if source != "" {
+		msg = fmt.Sprintf("%s %s", msg, source)
+	}
+	if line > 0 {
+		msg = fmt.Sprintf("%s line %d", msg, line)
+	}
+	return &InputException{
+		Line:    line,
+		Source:  source,
+		message: fmt.Sprintf("%s %s", msg, cause.Error()),
+		cause:   cause,
+	}
+}
+func (e *InputException) Error() string {
+	return e.message
+}
+func (e *InputException) Unwrap() error {
+	return e.cause
+}
+
+// Create a new ConversionException for the cell at [col] and [row]
+func NewConversionException(col, row int, msg string, cause error) *ConversionException {
+	if cause != nil {
+		msg = fmt.Sprintf("%s, %s", msg, cause.Error())
+	}
+	return &ConversionException{
+		Col:     col,
+		Row:     row,
+		message: fmt.Sprintf("errors/ConversionException: col=%d row=%d %s", col, row, msg),
+		cause:   cause,
+	}
+}
+func (e *ConversionException) Error() string {
+	return e.message
+}
+func (e *ConversionException) Unwrap() error {
+	return e.cause
+}
+
+// Create a new OutputException
+func NewOutputException(msg string, cause error) *OutputException {
+	if cause != nil {
+		msg = fmt.Sprintf("%s, %s", msg, cause.Error())
+	}
+	return &OutputException{
+		message: fmt.Sprintf("errors/OutputException: %s", msg),
+		cause:   cause,
+	}
+}
+func (e *OutputException) Error() string {
+	return e.message
+}
+func (e *OutputException) Unwrap() error {
+	return e.cause
+}
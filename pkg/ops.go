@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"context"
 	"log"
 	"time"
 
@@ -15,6 +16,13 @@ type (
 		// this results in a map with the row as the index and the expression result as the value
 		Apply() (map[uint32]interface{}, FieldType)
 	}
+	// An operator evaluated with a context, evaluation stops once the context is done and the context's error is returned.
+	// Evaluation errors (ex. mismatched types) are returned rather than logged.
+	// All operators of this package implement ContextOperator, see [Evaluate]
+	ContextOperator interface {
+		Operator
+		ApplyContext(ctx context.Context) (map[uint32]interface{}, FieldType, error)
+	}
 	Lt struct {
 		Lhs, Rhs Composer
 	}
@@ -72,13 +80,19 @@ func assertTypeIn(ts []FieldType, l, r Composer) (*FieldType, error) {
 // Applies the function to each left and right node pair. The right hand side may be a single fixed value,
 // in which case every value on the left is compared to a single right hand side value.
 // Results are gathered in a map where the key is the row index and the value is the result of the function
-func applyToT(t interface{}, out chan map[uint32]interface{}, op func(l, r interface{}) interface{}, l, r Composer) {
+// Application stops once [ctx] is done, a nil result is sent.
+func applyToT(ctx context.Context, t interface{}, out chan map[uint32]interface{}, op func(l, r interface{}) interface{}, l, r Composer) {
 	var (
 		results = make(map[uint32]interface{})
 		fixed   = r.Max() == 0
+		applied = 0
 	)
 	excludes := l.(Editor).Excludes()
 	for _, ll := range *l.Children() {
+		if applied++; applied%1024 == 0 && ctx.Err() != nil {
+			out <- nil
+			return
+		}
 		var (
 			lv = ll.Value()
 			rv interface{}
@@ -211,6 +225,26 @@ func applyToT(t interface{}, out chan map[uint32]interface{}, op func(l, r inter
 			} else {
 				results[row] = op(lv, rv)
 			}
+		case Decimal:
+			if _, ok := lv.(Decimal); !ok {
+				log.Printf("can not cast \"%v\" to decimal", lv)
+				results[row] = nil
+			} else if _, ok := rv.(Decimal); !ok {
+				log.Printf("can not cast \"%v\" to decimal", rv)
+				results[row] = nil
+			} else {
+				results[row] = op(lv, rv)
+			}
+		case Category:
+			if _, ok := lv.(Category); !ok {
+				log.Printf("can not cast \"%v\" to category", lv)
+				results[row] = nil
+			} else if _, ok := rv.(Category); !ok {
+				log.Printf("can not cast \"%v\" to category", rv)
+				results[row] = nil
+			} else {
+				results[row] = op(lv, rv)
+			}
 		case string:
 			if _, ok := lv.(string); !ok {
 				log.Printf("can not cast \"%v\" to string", lv)
@@ -238,94 +272,105 @@ func applyToT(t interface{}, out chan map[uint32]interface{}, op func(l, r inter
 	out <- results
 }
 func (lt Lt) Apply() (map[uint32]interface{}, FieldType) {
+	return apply(lt)
+}
+func (lt Lt) ApplyContext(ctx context.Context) (map[uint32]interface{}, FieldType, error) {
 	var (
 		t       *FieldType
 		err     error
 		outType = BOOL
 	)
 	if t, err = assertTypeIn(
-		[]FieldType{UINT8, UINT16, UINT32, UINT64, INT8, INT16, INT32, INT64, TIMESTAMP, FLOAT32, FLOAT64, DATE, STRING}, lt.Lhs, lt.Rhs); err != nil {
-		log.Fatal(err)
+		[]FieldType{UINT8, UINT16, UINT32, UINT64, INT8, INT16, INT32, INT64, TIMESTAMP, FLOAT32, FLOAT64, DECIMAL, DATE, STRING}, lt.Lhs, lt.Rhs); err != nil {
+		return nil, BOOL, err
 	}
-	out := make(chan map[uint32]interface{})
+	out := make(chan map[uint32]interface{}, 1)
 	switch *t {
 	case STRING:
-		go applyToT("", out, func(lv, rv interface{}) interface{} { return lv.(string) < rv.(string) }, lt.Lhs, lt.Rhs)
+		go applyToT(ctx, "", out, func(lv, rv interface{}) interface{} { return lv.(string) < rv.(string) }, lt.Lhs, lt.Rhs)
 	case UINT64:
-		go applyToT(uint64(0), out, func(l, r interface{}) interface{} { return l.(uint64) < r.(uint64) }, lt.Lhs, lt.Rhs)
+		go applyToT(ctx, uint64(0), out, func(l, r interface{}) interface{} { return l.(uint64) < r.(uint64) }, lt.Lhs, lt.Rhs)
 	case UINT32:
-		go applyToT(uint32(0), out, func(l, r interface{}) interface{} { return l.(uint) < r.(uint) }, lt.Lhs, lt.Rhs)
+		go applyToT(ctx, uint32(0), out, func(l, r interface{}) interface{} { return l.(uint32) < r.(uint32) }, lt.Lhs, lt.Rhs)
 	case UINT16:
-		go applyToT(uint16(0), out, func(l, r interface{}) interface{} { return l.(uint) < r.(uint) }, lt.Lhs, lt.Rhs)
+		go applyToT(ctx, uint16(0), out, func(l, r interface{}) interface{} { return l.(uint16) < r.(uint16) }, lt.Lhs, lt.Rhs)
 	case UINT8:
-		go applyToT(uint8(8), out, func(l, r interface{}) interface{} { return l.(uint) < r.(uint) }, lt.Lhs, lt.Rhs)
+		go applyToT(ctx, uint8(0), out, func(l, r interface{}) interface{} { return l.(uint8) < r.(uint8) }, lt.Lhs, lt.Rhs)
 	case INT64:
-		go applyToT(int64(0), out, func(l, r interface{}) interface{} { return l.(int64) < r.(int64) }, lt.Lhs, lt.Rhs)
+		go applyToT(ctx, int64(0), out, func(l, r interface{}) interface{} { return l.(int64) < r.(int64) }, lt.Lhs, lt.Rhs)
 	case INT32:
-		go applyToT(int32(0), out, func(l, r interface{}) interface{} { return l.(int) < r.(int) }, lt.Lhs, lt.Rhs)
+		go applyToT(ctx, int32(0), out, func(l, r interface{}) interface{} { return l.(int32) < r.(int32) }, lt.Lhs, lt.Rhs)
 	case INT16:
-		go applyToT(int16(0), out, func(l, r interface{}) interface{} { return l.(int) < r.(int) }, lt.Lhs, lt.Rhs)
+		go applyToT(ctx, int16(0), out, func(l, r interface{}) interface{} { return l.(int16) < r.(int16) }, lt.Lhs, lt.Rhs)
 	case INT8:
-		go applyToT(int8(8), out, func(l, r interface{}) interface{} { return l.(int) < r.(int) }, lt.Lhs, lt.Rhs)
+		go applyToT(ctx, int8(0), out, func(l, r interface{}) interface{} { return l.(int8) < r.(int8) }, lt.Lhs, lt.Rhs)
 	case FLOAT32:
-		go applyToT(float32(0), out, func(l, r interface{}) interface{} { return l.(float32) < r.(float32) }, lt.Lhs, lt.Rhs)
+		go applyToT(ctx, float32(0), out, func(l, r interface{}) interface{} { return l.(float32) < r.(float32) }, lt.Lhs, lt.Rhs)
 	case FLOAT64:
-		go applyToT(float64(0), out, func(l, r interface{}) interface{} { return l.(float64) < r.(float64) }, lt.Lhs, lt.Rhs)
+		go applyToT(ctx, float64(0), out, func(l, r interface{}) interface{} { return l.(float64) < r.(float64) }, lt.Lhs, lt.Rhs)
+	case DECIMAL:
+		go applyToT(ctx, Decimal{}, out, func(l, r interface{}) interface{} { return l.(Decimal).Cmp(r.(Decimal)) < 0 }, lt.Lhs, lt.Rhs)
 	case DATE:
 		fallthrough
 	case TIMESTAMP:
-		go applyToT(time.Time{}, out, func(lv, rv interface{}) interface{} { return lv.(time.Time).Unix() < rv.(time.Time).Unix() }, lt.Lhs, lt.Rhs)
+		go applyToT(ctx, time.Time{}, out, func(lv, rv interface{}) interface{} { return lv.(time.Time).Unix() < rv.(time.Time).Unix() }, lt.Lhs, lt.Rhs)
 	default:
-		log.Fatal("can not apply unknown in expression")
+		return nil, BOOL, errors.New("can not apply unknown in expression")
 	}
-	v := <-out
-	return v, outType
+	return result(ctx, out, outType)
 }
 func (gt Gt) Apply() (map[uint32]interface{}, FieldType) {
+	return apply(gt)
+}
+func (gt Gt) ApplyContext(ctx context.Context) (map[uint32]interface{}, FieldType, error) {
 	var (
 		t   *FieldType
 		err error
 	)
 	if t, err = assertTypeIn(
-		[]FieldType{UINT8, UINT16, UINT32, UINT64, INT8, INT16, INT32, INT64, TIMESTAMP, FLOAT32, FLOAT64, DATE, STRING}, gt.Lhs, gt.Rhs); err != nil {
-		log.Fatal(err)
+		[]FieldType{UINT8, UINT16, UINT32, UINT64, INT8, INT16, INT32, INT64, TIMESTAMP, FLOAT32, FLOAT64, DECIMAL, DATE, STRING}, gt.Lhs, gt.Rhs); err != nil {
+		return nil, BOOL, err
 	}
-	out := make(chan map[uint32]interface{})
+	out := make(chan map[uint32]interface{}, 1)
 	outType := BOOL
 	switch *t {
 	case STRING:
-		go applyToT("", out, func(lv, rv interface{}) interface{} { return lv.(string) > rv.(string) }, gt.Lhs, gt.Rhs)
+		go applyToT(ctx, "", out, func(lv, rv interface{}) interface{} { return lv.(string) > rv.(string) }, gt.Lhs, gt.Rhs)
 	case UINT64:
-		go applyToT(uint64(0), out, func(l, r interface{}) interface{} { return l.(uint64) > r.(uint64) }, gt.Lhs, gt.Rhs)
+		go applyToT(ctx, uint64(0), out, func(l, r interface{}) interface{} { return l.(uint64) > r.(uint64) }, gt.Lhs, gt.Rhs)
 	case UINT32:
-		go applyToT(uint32(0), out, func(l, r interface{}) interface{} { return l.(uint) > r.(uint) }, gt.Lhs, gt.Rhs)
+		go applyToT(ctx, uint32(0), out, func(l, r interface{}) interface{} { return l.(uint32) > r.(uint32) }, gt.Lhs, gt.Rhs)
 	case UINT16:
-		go applyToT(uint16(0), out, func(l, r interface{}) interface{} { return l.(uint) > r.(uint) }, gt.Lhs, gt.Rhs)
+		go applyToT(ctx, uint16(0), out, func(l, r interface{}) interface{} { return l.(uint16) > r.(uint16) }, gt.Lhs, gt.Rhs)
 	case UINT8:
-		go applyToT(uint8(8), out, func(l, r interface{}) interface{} { return l.(uint) > r.(uint) }, gt.Lhs, gt.Rhs)
+		go applyToT(ctx, uint8(0), out, func(l, r interface{}) interface{} { return l.(uint8) > r.(uint8) }, gt.Lhs, gt.Rhs)
 	case INT64:
-		go applyToT(int64(0), out, func(l, r interface{}) interface{} { return l.(int64) > r.(int64) }, gt.Lhs, gt.Rhs)
+		go applyToT(ctx, int64(0), out, func(l, r interface{}) interface{} { return l.(int64) > r.(int64) }, gt.Lhs, gt.Rhs)
 	case INT32:
-		go applyToT(int32(0), out, func(l, r interface{}) interface{} { return l.(int) > r.(int) }, gt.Lhs, gt.Rhs)
+		go applyToT(ctx, int32(0), out, func(l, r interface{}) interface{} { return l.(int32) > r.(int32) }, gt.Lhs, gt.Rhs)
 	case INT16:
-		go applyToT(int16(0), out, func(l, r interface{}) interface{} { return l.(int) > r.(int) }, gt.Lhs, gt.Rhs)
+		go applyToT(ctx, int16(0), out, func(l, r interface{}) interface{} { return l.(int16) > r.(int16) }, gt.Lhs, gt.Rhs)
 	case INT8:
-		go applyToT(int8(8), out, func(l, r interface{}) interface{} { return l.(int) > r.(int) }, gt.Lhs, gt.Rhs)
+		go applyToT(ctx, int8(0), out, func(l, r interface{}) interface{} { return l.(int8) > r.(int8) }, gt.Lhs, gt.Rhs)
 	case FLOAT32:
-		go applyToT(float32(0), out, func(l, r interface{}) interface{} { return l.(float32) > r.(float32) }, gt.Lhs, gt.Rhs)
+		go applyToT(ctx, float32(0), out, func(l, r interface{}) interface{} { return l.(float32) > r.(float32) }, gt.Lhs, gt.Rhs)
 	case FLOAT64:
-		go applyToT(float64(0), out, func(l, r interface{}) interface{} { return l.(float64) > r.(float64) }, gt.Lhs, gt.Rhs)
+		go applyToT(ctx, float64(0), out, func(l, r interface{}) interface{} { return l.(float64) > r.(float64) }, gt.Lhs, gt.Rhs)
+	case DECIMAL:
+		go applyToT(ctx, Decimal{}, out, func(l, r interface{}) interface{} { return l.(Decimal).Cmp(r.(Decimal)) > 0 }, gt.Lhs, gt.Rhs)
 	case DATE:
 		fallthrough
 	case TIMESTAMP:
-		go applyToT(time.Time{}, out, func(lv, rv interface{}) interface{} { return lv.(time.Time).Unix() > rv.(time.Time).Unix() }, gt.Lhs, gt.Rhs)
+		go applyToT(ctx, time.Time{}, out, func(lv, rv interface{}) interface{} { return lv.(time.Time).Unix() > rv.(time.Time).Unix() }, gt.Lhs, gt.Rhs)
 	default:
-		log.Fatal("can not apply unknown in expression")
+		return nil, BOOL, errors.New("can not apply unknown in expression")
 	}
-	v := <-out
-	return v, outType
+	return result(ctx, out, outType)
 }
 func (eq Eq) Apply() (map[uint32]interface{}, FieldType) {
+	return apply(eq)
+}
+func (eq Eq) ApplyContext(ctx context.Context) (map[uint32]interface{}, FieldType, error) {
 	var (
 		t   *FieldType
 		err error
@@ -336,75 +381,92 @@ func (eq Eq) Apply() (map[uint32]interface{}, FieldType) {
 			row := uint32(i & 0xFFFFFFFF)
 			nm[row] = v
 		}
-		return nm, BOOL
+		return nm, BOOL, nil
 	}
 	if t, err = assertTypeIn(
-		[]FieldType{UINT8, UINT16, UINT32, UINT64, INT8, INT16, INT32, INT64, TIMESTAMP, FLOAT32, FLOAT64, DATE, STRING, BOOL}, eq.Lhs, eq.Rhs); err != nil {
-		log.Fatal(err)
+		[]FieldType{UINT8, UINT16, UINT32, UINT64, INT8, INT16, INT32, INT64, TIMESTAMP, FLOAT32, FLOAT64, DECIMAL, DATE, STRING, BOOL, ENUM}, eq.Lhs, eq.Rhs); err != nil {
+		return nil, BOOL, err
 	}
-	out := make(chan map[uint32]interface{})
+	out := make(chan map[uint32]interface{}, 1)
 	outType := BOOL
 	switch *t {
 	case STRING:
-		go applyToT("", out, func(lv, rv interface{}) interface{} { return lv.(string) == rv.(string) }, eq.Lhs, eq.Rhs)
+		go applyToT(ctx, "", out, func(lv, rv interface{}) interface{} { return lv.(string) == rv.(string) }, eq.Lhs, eq.Rhs)
 	case UINT64:
-		go applyToT(uint64(0), out, func(l, r interface{}) interface{} { return l.(uint64) == r.(uint64) }, eq.Lhs, eq.Rhs)
+		go applyToT(ctx, uint64(0), out, func(l, r interface{}) interface{} { return l.(uint64) == r.(uint64) }, eq.Lhs, eq.Rhs)
 	case UINT32:
-		go applyToT(uint32(0), out, func(l, r interface{}) interface{} { return l.(uint) == r.(uint) }, eq.Lhs, eq.Rhs)
+		go applyToT(ctx, uint32(0), out, func(l, r interface{}) interface{} { return l.(uint32) == r.(uint32) }, eq.Lhs, eq.Rhs)
 	case UINT16:
-		go applyToT(uint16(0), out, func(l, r interface{}) interface{} { return l.(uint) == r.(uint) }, eq.Lhs, eq.Rhs)
+		go applyToT(ctx, uint16(0), out, func(l, r interface{}) interface{} { return l.(uint16) == r.(uint16) }, eq.Lhs, eq.Rhs)
 	case UINT8:
-		go applyToT(uint8(8), out, func(l, r interface{}) interface{} { return l.(uint) == r.(uint) }, eq.Lhs, eq.Rhs)
+		go applyToT(ctx, uint8(0), out, func(l, r interface{}) interface{} { return l.(uint8) == r.(uint8) }, eq.Lhs, eq.Rhs)
 	case INT64:
-		go applyToT(int64(0), out, func(l, r interface{}) interface{} { return l.(int64) == r.(int64) }, eq.Lhs, eq.Rhs)
+		go applyToT(ctx, int64(0), out, func(l, r interface{}) interface{} { return l.(int64) == r.(int64) }, eq.Lhs, eq.Rhs)
 	case INT32:
-		go applyToT(int32(0), out, func(l, r interface{}) interface{} { return l.(int) == r.(int) }, eq.Lhs, eq.Rhs)
+		go applyToT(ctx, int32(0), out, func(l, r interface{}) interface{} { return l.(int32) == r.(int32) }, eq.Lhs, eq.Rhs)
 	case INT16:
-		go applyToT(int16(0), out, func(l, r interface{}) interface{} { return l.(int) == r.(int) }, eq.Lhs, eq.Rhs)
+		go applyToT(ctx, int16(0), out, func(l, r interface{}) interface{} { return l.(int16) == r.(int16) }, eq.Lhs, eq.Rhs)
 	case INT8:
-		go applyToT(int8(8), out, func(l, r interface{}) interface{} { return l.(int) == r.(int) }, eq.Lhs, eq.Rhs)
+		go applyToT(ctx, int8(0), out, func(l, r interface{}) interface{} { return l.(int8) == r.(int8) }, eq.Lhs, eq.Rhs)
 	case FLOAT32:
-		go applyToT(float32(0), out, func(l, r interface{}) interface{} { return l.(float32) == r.(float32) }, eq.Lhs, eq.Rhs)
+		go applyToT(ctx, float32(0), out, func(l, r interface{}) interface{} { return l.(float32) == r.(float32) }, eq.Lhs, eq.Rhs)
 	case FLOAT64:
-		go applyToT(float64(0), out, func(l, r interface{}) interface{} { return l.(float64) == r.(float64) }, eq.Lhs, eq.Rhs)
+		go applyToT(ctx, float64(0), out, func(l, r interface{}) interface{} { return l.(float64) == r.(float64) }, eq.Lhs, eq.Rhs)
+	case DECIMAL:
+		go applyToT(ctx, Decimal{}, out, func(l, r interface{}) interface{} { return l.(Decimal).Cmp(r.(Decimal)) == 0 }, eq.Lhs, eq.Rhs)
 	case DATE:
 		fallthrough
 	case TIMESTAMP:
-		go applyToT(time.Time{}, out, func(lv, rv interface{}) interface{} { return lv.(time.Time).Unix() == rv.(time.Time).Unix() }, eq.Lhs, eq.Rhs)
+		go applyToT(ctx, time.Time{}, out, func(lv, rv interface{}) interface{} { return lv.(time.Time).Unix() == rv.(time.Time).Unix() }, eq.Lhs, eq.Rhs)
 	case BOOL:
-		go applyToT(true, out, func(l, r interface{}) interface{} {
+		go applyToT(ctx, true, out, func(l, r interface{}) interface{} {
 			return l.(bool) == r.(bool)
 		}, eq.Lhs, eq.Rhs)
+	case ENUM:
+		// categories of different fields are equal by value
+		go applyToT(ctx, Category{}, out, func(l, r interface{}) interface{} { return l.(Category).String() == r.(Category).String() }, eq.Lhs, eq.Rhs)
 	default:
-		log.Fatal("can not apply unknown in expression")
+		return nil, BOOL, errors.New("can not apply unknown in expression")
 	}
-	v := <-out
-	return v, outType
+	return result(ctx, out, outType)
 }
 func (not Not) Apply() (map[uint32]interface{}, FieldType) {
-	nm, t := not.Value.Apply()
+	return apply(not)
+}
+func (not Not) ApplyContext(ctx context.Context) (map[uint32]interface{}, FieldType, error) {
+	nm, t, err := Evaluate(ctx, not.Value)
+	if err != nil {
+		return nil, t, err
+	}
 	out := make(map[uint32]interface{})
 	for i, v := range nm {
 		out[i] = !v.(bool)
 	}
-	return out, t
+	return out, t, nil
 }
 func (t True) Apply() (map[uint32]interface{}, FieldType) {
 	return map[uint32]interface{}{1: true}, BOOL
 }
+func (t True) ApplyContext(ctx context.Context) (map[uint32]interface{}, FieldType, error) {
+	m, ft := t.Apply()
+	return m, ft, ctx.Err()
+}
 func (f False) Apply() (map[uint32]interface{}, FieldType) {
 	return map[uint32]interface{}{1: false}, BOOL
 }
+func (f False) ApplyContext(ctx context.Context) (map[uint32]interface{}, FieldType, error) {
+	m, ft := f.Apply()
+	return m, ft, ctx.Err()
+}
 func (c If) Apply() (map[uint32]interface{}, FieldType) {
+	return apply(c)
+}
+func (c If) ApplyContext(ctx context.Context) (map[uint32]interface{}, FieldType, error) {
 	var (
 		async = func(prop string, out chan Pair, op Operator) {
-			m, t := op.Apply()
-			if t != BOOL {
-				log.Fatal("Logical statements must evaluate to a boolean")
-			}
-			out <- Pair{prop, m}
+			out <- Pair{prop, boolean(ctx, op)}
 		}
-		coll  = make(chan Pair)
+		coll  = make(chan Pair, 3)
 		cond  map[uint32]interface{}
 		t     map[uint32]interface{}
 		e     map[uint32]interface{}
@@ -415,7 +477,12 @@ func (c If) Apply() (map[uint32]interface{}, FieldType) {
 	go async("else", coll, c.Else)
 	for {
 		select {
+		case <-ctx.Done():
+			return nil, BOOL, ctx.Err()
 		case m := <-coll:
+			if err, ok := m.Second.(error); ok {
+				return nil, BOOL, err
+			}
 			if m.First == "cond" {
 				cond = m.Second.(map[uint32]interface{})
 			} else if m.First == "then" {
@@ -473,21 +540,17 @@ func (c If) Apply() (map[uint32]interface{}, FieldType) {
 					}
 				}
 
-				return final, BOOL
+				return final, BOOL, nil
 			}
 		}
 	}
 }
-func logical(operator Operator, fn func(bool, bool) bool) (map[uint32]interface{}, FieldType) {
+func logical(ctx context.Context, operator Operator, fn func(bool, bool) bool) (map[uint32]interface{}, FieldType, error) {
 	var (
 		async = func(prop string, out chan Pair, op Operator) {
-			m, t := op.Apply()
-			if t != BOOL {
-				log.Fatal("Logical statements must evaluate to a boolean")
-			}
-			out <- Pair{prop, m}
+			out <- Pair{prop, boolean(ctx, op)}
 		}
-		coll                         = make(chan Pair)
+		coll                         = make(chan Pair, 2)
 		final                        = make(map[uint32]interface{})
 		l     map[uint32]interface{} = nil
 		r     map[uint32]interface{} = nil
@@ -500,12 +563,17 @@ func logical(operator Operator, fn func(bool, bool) bool) (map[uint32]interface{
 		go async("l", coll, operator.(Or).Lhs)
 		go async("r", coll, operator.(Or).Rhs)
 	default:
-		log.Fatal("logical operations must be And / Or")
+		return nil, BOOL, errors.New("logical operations must be And / Or")
 	}
 
 	for {
 		select {
+		case <-ctx.Done():
+			return nil, BOOL, ctx.Err()
 		case m := <-coll:
+			if err, ok := m.Second.(error); ok {
+				return nil, BOOL, err
+			}
 			if m.First == "l" {
 				l = m.Second.(map[uint32]interface{})
 			} else {
@@ -519,18 +587,82 @@ func logical(operator Operator, fn func(bool, bool) bool) (map[uint32]interface{
 						final[i] = fn(v.(bool), r[i].(bool))
 					}
 				}
-				return final, BOOL
+				return final, BOOL, nil
 			}
 		}
 	}
 }
 func (or Or) Apply() (map[uint32]interface{}, FieldType) {
-	return logical(or, func(first bool, second bool) bool {
+	return apply(or)
+}
+func (or Or) ApplyContext(ctx context.Context) (map[uint32]interface{}, FieldType, error) {
+	return logical(ctx, or, func(first bool, second bool) bool {
 		return first || second
 	})
 }
 func (a And) Apply() (map[uint32]interface{}, FieldType) {
-	return logical(a, func(first bool, second bool) bool {
+	return apply(a)
+}
+func (a And) ApplyContext(ctx context.Context) (map[uint32]interface{}, FieldType, error) {
+	return logical(ctx, a, func(first bool, second bool) bool {
 		return first && second
 	})
 }
+
+// Evaluate an expression, evaluation stops once [ctx] is done and the context's error is returned.
+// An [Operator] not implementing [ContextOperator] is applied in a goroutine of its own, the goroutine is
+// released once the operator returns.
+func Evaluate(ctx context.Context, op Operator) (map[uint32]interface{}, FieldType, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, UNKNOWN, err
+	}
+	if co, ok := op.(ContextOperator); ok {
+		return co.ApplyContext(ctx)
+	}
+	out := make(chan Pair, 1)
+	go func() {
+		m, t := op.Apply()
+		out <- Pair{m, t}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, UNKNOWN, ctx.Err()
+	case p := <-out:
+		return p.First.(map[uint32]interface{}), p.Second.(FieldType), nil
+	}
+}
+
+// Apply an operator without a context. An evaluation error is logged and an empty result of type UNKNOWN is returned.
+func apply(op ContextOperator) (map[uint32]interface{}, FieldType) {
+	m, t, err := op.ApplyContext(context.Background())
+	if err != nil {
+		log.Printf("pkg/ops: %s", err.Error())
+		return map[uint32]interface{}{}, UNKNOWN
+	}
+	return m, t
+}
+
+// Evaluate a logical statement, the result map or an error is returned
+func boolean(ctx context.Context, op Operator) interface{} {
+	m, t, err := Evaluate(ctx, op)
+	if err != nil {
+		return err
+	}
+	if t != BOOL {
+		return errors.New("Logical statements must evaluate to a boolean")
+	}
+	return m
+}
+
+// Wait for the result of an application
+func result(ctx context.Context, out chan map[uint32]interface{}, t FieldType) (map[uint32]interface{}, FieldType, error) {
+	select {
+	case <-ctx.Done():
+		return nil, t, ctx.Err()
+	case v := <-out:
+		if v == nil {
+			return nil, t, ctx.Err()
+		}
+		return v, t, nil
+	}
+}
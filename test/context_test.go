@@ -0,0 +1,105 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/loanpal-engineering/exttra/io/input"
+	"github.com/loanpal-engineering/exttra/io/output"
+	"github.com/loanpal-engineering/exttra/parser"
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+	"github.com/loanpal-engineering/exttra/view"
+)
+
+func TestContext(t *testing.T) {
+	var src strings.Builder
+	src.WriteString("Loan ID,Amount\n")
+	for ii := 1; ii <= 500; ii++ {
+		fmt.Fprintf(&src, "%d,%d\n", ii, ii*10)
+	}
+	str, err := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	num, err := types.NewField(pkg.INT64, &pkg.Nullable{Allowed: false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := types.NewSchema(
+		types.Column("Loan ID", str, true),
+		types.Column("Amount", num, true))
+	newInput := func() *input.Input {
+		in := input.Csv(strings.NewReader(src.String()), s)
+		return &in
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err = parser.NewParser(newInput()).ValidateContext(cancelled, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("ValidateContext: expected context.Canceled but got %v", err)
+	}
+	p := parser.NewParser(newInput())
+	if err = p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = p.ParseContext(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("ParseContext: expected context.Canceled but got %v", err)
+	}
+
+	// stop a stream part way through, the stream goroutine is released without reading the pending batch
+	p = parser.NewParser(newInput())
+	if err = p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	batches, errs := p.BatchesContext(ctx, 100)
+	<-batches
+	stop()
+	if err = <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("BatchesContext: expected context.Canceled but got %v", err)
+	}
+
+	p = parser.NewParser(newInput())
+	if err = p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = view.NewViewContext(cancelled, view.Select("Loan ID"), view.From(root),
+		view.Where(pkg.Gt{Lhs: root.Find("Amount"), Rhs: root.Find("Amount")}))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("NewViewContext: expected context.Canceled but got %v", err)
+	}
+	// an invalid expression is returned rather than exiting
+	err = view.NewView(view.Select("Loan ID"), view.From(root),
+		view.Where(pkg.Gt{Lhs: root.Find("Loan ID"), Rhs: root.Find("Amount")}))
+	if err == nil {
+		t.Error("expected an error comparing a string to an int64")
+	}
+
+	if err = output.Csv(root, new(bytes.Buffer)).FlushContext(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("FlatFile.FlushContext: expected context.Canceled but got %v", err)
+	}
+	type shape struct {
+		Id     string
+		Amount int64
+	}
+	outParam := make([]interface{}, 0)
+	out := output.Mem(root, shape{}, &outParam, output.Alias("Loan ID", "Id"), output.Alias("Amount", "Amount"))
+	if err = out.FlushContext(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Memory.FlushContext: expected context.Canceled but got %v", err)
+	}
+	if err = out.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(outParam) != 500 {
+		t.Errorf("expected 500 rows but got %d", len(outParam))
+	}
+}
@@ -147,3 +147,99 @@ func testDate(i interface{}) {
 
 	}
 }
+
+func TestTimePolicy(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	utc := func(s string) time.Time {
+		v, _ := time.Parse(time.RFC3339, s)
+		return v
+	}
+	table := []struct {
+		t         pkg.FieldType
+		overrides []types.FieldOverride
+		in        string
+		expect    time.Time
+		fail      bool
+	}{
+		// day first vendor
+		{pkg.DATE, []types.FieldOverride{types.Layouts("02/01/2006")}, "04/02/2014", utc("2014-02-04T00:00:00Z"), false},
+		{pkg.DATE, []types.FieldOverride{types.Layouts("02/01/2006")}, "2014-02-04", utc("2014-02-04T00:00:00Z"), false},
+		{pkg.DATE, []types.FieldOverride{types.Layouts("02/01/2006"), types.Strict()}, "2014-02-04", time.Time{}, true},
+		// without a layout the order of an ambiguous date is not guessed
+		{pkg.DATE, []types.FieldOverride{types.Strict()}, "04/02/2014", time.Time{}, true},
+		{pkg.DATE, []types.FieldOverride{types.Strict()}, "2014-02-04", utc("2014-02-04T00:00:00Z"), false},
+		// a date is truncated, a timestamp is not
+		{pkg.DATE, nil, "2019-01-02 12:00:00", utc("2019-01-02T00:00:00Z"), false},
+		{pkg.TIMESTAMP, nil, "2019-01-02 12:00:00", utc("2019-01-02T12:00:00Z"), false},
+		// local timestamps are read in the source zone, an explicit offset is kept
+		{pkg.TIMESTAMP, []types.FieldOverride{types.SourceZone(ny)}, "2019-01-02 10:30:00", utc("2019-01-02T15:30:00Z"), false},
+		{pkg.TIMESTAMP, []types.FieldOverride{types.SourceZone(ny)}, "2019-01-02T10:30:00Z", utc("2019-01-02T10:30:00Z"), false},
+		{pkg.TIMESTAMP, []types.FieldOverride{types.SourceZone(ny), types.Layouts("01/02/2006 15:04")}, "01/02/2019 10:30", utc("2019-01-02T15:30:00Z"), false},
+		// a date is the calendar date in the target zone
+		{pkg.DATE, []types.FieldOverride{types.TargetZone(ny)}, "2019-01-02T03:00:00Z", time.Date(2019, 1, 1, 0, 0, 0, 0, ny), false},
+	}
+	for _, test := range table {
+		field, err := types.NewField(test.t, &pkg.Nullable{Allowed: false}, test.overrides...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _, err := field.Convert(&test.in)
+		if test.fail {
+			if err == nil {
+				t.Errorf("%s %s: expected an error but got %v", test.t, test.in, v)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %s: %v", test.t, test.in, err)
+		} else if !v.(time.Time).Equal(test.expect) {
+			t.Errorf("%s %s: expected %v but got %v", test.t, test.in, test.expect, v)
+		}
+	}
+	if _, err = types.NewField(pkg.STRING, &pkg.Nullable{}, types.Strict()); err == nil {
+		t.Error("expected an error setting a date/time policy on a string field")
+	}
+
+	// dates are written without a time of day, defects name the value
+	date, _ := types.NewField(pkg.DATE, &pkg.Nullable{Allowed: true}, types.Layouts("02/01/2006"), types.Strict())
+	stamp, _ := types.NewField(pkg.TIMESTAMP, &pkg.Nullable{Allowed: true}, types.SourceZone(ny))
+	def := types.NewSchema(types.Column("Funded", date, true), types.Column("Created", stamp, true))
+	in := input.Csv(strings.NewReader("Funded,Created\n25/12/2019,2019-12-25 08:00:00\n12-25-2019,2019-12-26 08:00:00\n"), def)
+	p := parser.NewParser(&in)
+	if err = p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	before := pkg.NewDC().Count()
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = output.Csv(root, &buf).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Funded,Created\n2019-12-25,2019-12-25T08:00:00-05:00\n,2019-12-26T08:00:00-05:00\n"; buf.String() != expect {
+		t.Errorf("expected\n%s\nbut got\n%s", expect, buf.String())
+	}
+	if defects := (*pkg.NewDC().Coll())[before:]; len(defects) != 1 || !strings.Contains(defects[0].Msg, "12-25-2019") {
+		t.Errorf("expected a defect for 12-25-2019 but got %+v", defects)
+	}
+
+	// the policy is kept in a schema file
+	var yml bytes.Buffer
+	if err = types.WriteSchema(&yml, def, types.YAML); err != nil {
+		t.Fatal(err)
+	}
+	again, err := types.ReadSchema(&yml, types.YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layouts, _, _, strict := again.(*types.Schema).Cols()[0].Field.TimePolicy()
+	_, source, _, _ := again.(*types.Schema).Cols()[1].Field.TimePolicy()
+	if len(layouts) != 1 || layouts[0] != "02/01/2006" || !strict || source == nil || source.String() != "America/New_York" {
+		t.Errorf("unexpected date/time policy %v %v %v", layouts, strict, source)
+	}
+}
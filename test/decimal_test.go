@@ -0,0 +1,136 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/loanpal-engineering/exttra/data"
+	"github.com/loanpal-engineering/exttra/io/input"
+	"github.com/loanpal-engineering/exttra/io/output"
+	"github.com/loanpal-engineering/exttra/parser"
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+	"github.com/loanpal-engineering/exttra/view"
+)
+
+func TestDecimal(t *testing.T) {
+	for _, test := range []struct {
+		in, out string
+		scale   uint8
+	}{
+		{"$1,200.50", "1200.50", 2},
+		{"1,234,567.891", "1234567.89", 2},
+		{"(300.25)", "-300.25", 2},
+		{"-$0.1", "-0.10", 2},
+		{"12.345", "12.35", 2},
+		{"-12.345", "-12.35", 2},
+		{"7", "7", 0},
+		{".5", "0.5000", 4},
+	} {
+		v, err := types.DecimalConverter(18, test.scale)(&test.in)
+		if err != nil {
+			t.Errorf("%s: %v", test.in, err)
+			continue
+		}
+		if got := v.(pkg.Decimal).String(); got != test.out {
+			t.Errorf("%s: expected %s but got %s", test.in, test.out, got)
+		}
+	}
+	for _, in := range []string{"12a", "1.2.3", "(-5)", "$", "123456.00", "1,5", "1,2,3", "12,34.5", ",123", "1234,567"} {
+		if _, err := types.DecimalConverter(7, 2)(&in); err == nil {
+			t.Errorf("%s: expected a conversion error", in)
+		}
+	}
+	if d, err := pkg.ParseDecimal("999999999999999999.5", 0); err == nil {
+		t.Errorf("expected a value rounded to 19 digits to be an error but got %s", d)
+	}
+	if d, err := pkg.ParseDecimal("99999999999999999.5", 0); err != nil || d.String() != "100000000000000000" {
+		t.Errorf("expected 99999999999999999.5 to round to 18 digits but got %s %v", d, err)
+	}
+	a, _ := pkg.ParseDecimal("0.1", 2)
+	b, _ := pkg.ParseDecimal("0.2", 1)
+	if sum, err := a.Add(b); err != nil || sum.String() != "0.30" || sum.Cmp(pkg.Decimal{Unscaled: 3, Scale: 1}) != 0 {
+		t.Errorf("expected 0.1 + 0.2 to be 0.30 but got %v %v", sum, err)
+	}
+
+	amount, err := types.NewField(pkg.DECIMAL, &pkg.Nullable{Allowed: false}, types.Precision(12, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = types.NewField(pkg.FLOAT64, &pkg.Nullable{}, types.Precision(12, 2)); err == nil {
+		t.Error("expected an error setting the precision of a float field")
+	}
+	id, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: false})
+	def := types.NewSchema(types.Column("Loan ID", id, true, true), types.Column("Amount", amount, true))
+	src := "Loan ID,Amount\n1001,\"$1,200.50\"\n1002,(300.25)\n1003,0.10\n"
+	in := input.Csv(strings.NewReader(src), def)
+	p := parser.NewParser(&in)
+	if err = p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = output.Csv(root, &buf).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Loan ID,Amount\n1001,1200.50\n1002,-300.25\n1003,0.10\n"; buf.String() != expect {
+		t.Errorf("expected\n%s\nbut got\n%s", expect, buf.String())
+	}
+
+	type loan struct {
+		Id     string      `exttra:"Loan ID"`
+		Amount pkg.Decimal `exttra:"Amount"`
+	}
+	type text struct {
+		Id     string `exttra:"Loan ID"`
+		Amount string
+	}
+	if c := types.SchemaFromStruct(loan{}).(*types.Schema).Cols()[1]; c.Field.T != pkg.DECIMAL {
+		t.Errorf("expected a pkg.Decimal field to be a DECIMAL column but got %s", c.Field.T)
+	}
+	out := make([]interface{}, 0)
+	if err = output.Mem(root, loan{}, &out).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	texts := make([]interface{}, 0)
+	if err = output.Mem(root, text{}, &texts).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	amounts := make(map[string]string)
+	for _, v := range texts {
+		amounts[v.(text).Id] = v.(text).Amount
+	}
+	total := pkg.Decimal{Scale: 2}
+	for _, v := range out {
+		l := v.(loan)
+		if l.Amount.String() != amounts[l.Id] {
+			t.Errorf("%s: expected %s in both shapes but got %s", l.Id, l.Amount, amounts[l.Id])
+		}
+		if total, err = total.Add(l.Amount); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if total.String() != "900.35" {
+		t.Errorf("expected a total of 900.35 but got %s", total)
+	}
+
+	dt := pkg.DECIMAL
+	limit, err := data.NewNode(nil, data.V(pkg.Decimal{Unscaled: 1, Scale: 1}), data.Type(&dt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = view.NewView(view.Select("Loan ID"), view.From(root), view.Where(pkg.Gt{Lhs: root.Find("Amount"), Rhs: limit})); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err = output.Csv(root, &buf).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Loan ID\n1001\n"; buf.String() != expect {
+		t.Errorf("expected\n%s\nbut got\n%s", expect, buf.String())
+	}
+}
@@ -0,0 +1,147 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/loanpal-engineering/exttra/data"
+	"github.com/loanpal-engineering/exttra/io/input"
+	"github.com/loanpal-engineering/exttra/io/output"
+	"github.com/loanpal-engineering/exttra/parser"
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+	"github.com/loanpal-engineering/exttra/view"
+)
+
+func TestEnum(t *testing.T) {
+	id, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: false})
+	state, err := types.NewField(pkg.ENUM, &pkg.Nullable{Allowed: true},
+		types.Enum("CA", "NV"),
+		types.Synonyms("CA", "Calif.", "California"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := types.NewSchema(types.Column("Loan ID", id, true), types.Column("State", state, true))
+	src := "Loan ID,State\n1001,CA\n1002, calif. \n1003,CALIFORNIA\n1004,nv\n1005,TX\n1006,\n"
+	in := input.Csv(strings.NewReader(src), def)
+	p := parser.NewParser(&in)
+	if err = p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	before := pkg.NewDC().Count()
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defects := (*pkg.NewDC().Coll())[before:]
+	if len(defects) != 1 || defects[0].Row != 5 || !strings.Contains(defects[0].Msg, "\"TX\" is not one of CA, NV") {
+		t.Errorf("expected a defect for TX but got %+v", defects)
+	}
+	col := root.Find("State")
+	_, ci, _ := col.Id()
+	if v, ok := col.FindById(pkg.GenNodeId(ci, 4)).Value().(pkg.Category); !ok || v.Code != 1 || v.String() != "NV" {
+		t.Errorf("expected category 1 NV but got %v", col.FindById(pkg.GenNodeId(ci, 4)).Value())
+	}
+	var buf bytes.Buffer
+	if err = output.Csv(root, &buf).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Loan ID,State\n1001,CA\n1002,CA\n1003,CA\n1004,NV\n1005,\n1006,\n"; buf.String() != expect {
+		t.Errorf("expected\n%s\nbut got\n%s", expect, buf.String())
+	}
+
+	type loan struct {
+		Id    string `exttra:"Loan ID"`
+		State string `exttra:"State,enum=CA|NV"`
+	}
+	type code struct {
+		Id   string `exttra:"Loan ID"`
+		Code uint16
+	}
+	if c := types.SchemaFromStruct(loan{}).(*types.Schema).Cols()[1]; c.Field.T != pkg.ENUM {
+		t.Errorf("expected an enum column but got %s", c.Field.T)
+	}
+	out := make([]interface{}, 0)
+	if err = output.Mem(root, loan{}, &out).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	codes := make([]interface{}, 0)
+	if err = output.Mem(root, code{}, &codes, output.Alias("State", "Code")).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	// rows are written in any order
+	byId := make(map[string]uint16)
+	for _, v := range codes {
+		byId[v.(code).Id] = v.(code).Code
+	}
+	nv := 0
+	for _, v := range out {
+		if v.(loan).State == "NV" {
+			nv++
+		}
+		if s := v.(loan).State; s != "" && byId[v.(loan).Id] != map[string]uint16{"CA": 0, "NV": 1}[s] {
+			t.Errorf("unexpected code of %s", s)
+		}
+	}
+	if len(out) != 6 || nv != 1 {
+		t.Errorf("unexpected rows %v", out)
+	}
+
+	// categories are compared by value
+	categories, _ := state.Enum()
+	ca, _ := categories.Category("CA")
+	et := pkg.ENUM
+	rhs, _ := data.NewNode(nil, data.V(ca), data.Type(&et))
+	if err = view.NewView(view.Select("Loan ID"), view.From(root), view.Where(pkg.Eq{Lhs: root.Find("State"), Rhs: rhs})); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err = output.Csv(root, &buf).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Loan ID\n1001\n1002\n1003\n"; buf.String() != expect {
+		t.Errorf("expected\n%s\nbut got\n%s", expect, buf.String())
+	}
+
+	// the values are kept in a schema file
+	buf.Reset()
+	if err = types.WriteSchema(&buf, def, types.YAML); err != nil {
+		t.Fatal(err)
+	}
+	again, err := types.ReadSchema(&buf, types.YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := again.(*types.Schema).Cols()[1].Field
+	if v, _, err := read.Convert(&[]string{"California"}[0]); err != nil || v.(pkg.Category).String() != "CA" {
+		t.Errorf("expected California to read as CA but got %v %v", v, err)
+	}
+
+	if _, err = types.NewField(pkg.ENUM, &pkg.Nullable{}); err == nil {
+		t.Error("expected an error for an enum without values")
+	}
+	if _, err = types.NewField(pkg.ENUM, &pkg.Nullable{}, types.Enum("CA"), types.Synonyms("NV", "Nevada")); err == nil {
+		t.Error("expected an error for synonyms of an unknown value")
+	}
+	if _, err = types.NewField(pkg.ENUM, &pkg.Nullable{}, types.Enum("CA", "ca")); err == nil {
+		t.Error("expected an error for values equal ignoring case")
+	}
+	if _, err = types.NewField(pkg.ENUM, &pkg.Nullable{}, types.Enum("CA", "ca"), types.CaseSensitive()); err != nil {
+		t.Errorf("expected values differing by case to be accepted by a case sensitive enum but got %v", err)
+	}
+	identity := pkg.FieldLevelConverter(func(in *string, _ ...interface{}) (interface{}, error) { return *in, nil })
+	if _, err = types.NewField(pkg.ENUM, &pkg.Nullable{}, types.Enum("CA"), types.Override(types.Convert, identity)); err == nil {
+		t.Error("expected an error for a convert function on an enum")
+	}
+	lower, err := types.NewField(pkg.ENUM, &pkg.Nullable{}, types.Enum("CA", "NV"), types.Synonyms(" ca", "Calif."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _, err := lower.Convert(&[]string{"calif."}[0]); err != nil || v.(pkg.Category).String() != "CA" {
+		t.Errorf("expected calif. to read as CA but got %v %v", v, err)
+	}
+	if _, err = types.NewField(pkg.ENUM, &pkg.Nullable{}, types.Enum("CA"), types.Synonyms("ca", "Calif."), types.CaseSensitive()); err == nil {
+		t.Error("expected an error for synonyms of a value differing by case in a case sensitive enum")
+	}
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/loanpal-engineering/exttra/io/input"
+	"github.com/loanpal-engineering/exttra/io/output"
+	"github.com/loanpal-engineering/exttra/parser"
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+// A reader failing after the first [n] bytes
+type brokenReader struct {
+	src io.Reader
+	n   int
+}
+
+func (r *brokenReader) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		return 0, errors.New("connection reset")
+	}
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+	n, err := r.src.Read(p)
+	r.n -= n
+	return n, err
+}
+
+func TestErrors(t *testing.T) {
+	field, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: true})
+	src := "A,B\n1,2\n3,4\n"
+	var (
+		schemaErr  *pkg.SchemaException
+		missingErr *pkg.ColumnNotFoundException
+		inputErr   *pkg.InputException
+		outputErr  *pkg.OutputException
+		defect     *pkg.Defect
+	)
+
+	bad := types.NewSchema(types.Column("A", field, true), types.Alias("Z", "Zed"))
+	in := input.Csv(strings.NewReader(src), bad)
+	if err := parser.NewParser(&in).Validate(nil); !errors.As(err, &schemaErr) || schemaErr.Column != "Z" {
+		t.Errorf("expected a schema exception for column Z but got %v", err)
+	}
+
+	in = input.Csv(strings.NewReader(src), stringSchema(t, "A", "C"))
+	err := parser.NewParser(&in).Validate(nil)
+	if !errors.As(err, &missingErr) || strings.Join(missingErr.Columns, ",") != "C" {
+		t.Errorf("expected column C to be missing but got %v", err)
+	}
+	if !errors.As(err, &defect) {
+		t.Errorf("expected the missing column defect to be wrapped")
+	}
+
+	in = input.Csv(&brokenReader{src: strings.NewReader(strings.Repeat(src, 2000)), n: 5000}, stringSchema(t, "A", "B"))
+	p := parser.NewParser(&in)
+	if err = p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = p.Parse(); !errors.As(err, &inputErr) {
+		t.Errorf("expected an input exception but got %v", err)
+	}
+
+	in = input.Xlsx(strings.NewReader("not a workbook"), 0, stringSchema(t, "A"))
+	if err = parser.NewParser(&in).Validate(nil); err == nil {
+		t.Error("expected an invalid workbook error")
+	}
+
+	in = input.Csv(strings.NewReader(src), stringSchema(t, "A", "B"))
+	p = parser.NewParser(&in)
+	if err = p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = output.Csv(root, nil).Flush(); !errors.As(err, &outputErr) {
+		t.Errorf("expected an output exception but got %v", err)
+	}
+	if err = output.Csv(root, new(bytes.Buffer)).Flush(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err = types.NewField(pkg.CUSTOM, &pkg.Nullable{}); err == nil {
+		t.Error("expected a custom field without converters to fail")
+	}
+}
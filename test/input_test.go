@@ -0,0 +1,524 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loanpal-engineering/exttra/io/input"
+	"github.com/loanpal-engineering/exttra/io/output"
+	"github.com/loanpal-engineering/exttra/parser"
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+func stringSchema(t testing.TB, names ...string) types.Signature {
+	field, err := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := make([]types.Opt, 0, len(names))
+	for _, n := range names {
+		opts = append(opts, types.Column(n, field, true))
+	}
+	return types.NewSchema(opts...)
+}
+
+// parse the input and return the values of [col] in row order
+func parseColumn(t testing.TB, in input.Input, col string, opts ...parser.Opt) []string {
+	p := parser.NewParser(&in, opts...)
+	if err := p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := root.Find(col)
+	if c == nil {
+		t.Fatalf("column %s not found", col)
+	}
+	_, colIdx, _ := c.Id()
+	out := make([]string, 0, c.Max())
+	for row := uint32(1); uint64(row) <= c.Max(); row++ {
+		n := c.FindById(pkg.GenNodeId(colIdx, row))
+		if n == nil {
+			continue
+		}
+		if n.Value() == nil {
+			out = append(out, "")
+			continue
+		}
+		out = append(out, *types.SimpleToString(n.Value()))
+	}
+	return out
+}
+
+func TestCsvDialects(t *testing.T) {
+	table := []struct {
+		name   string
+		input  func(s types.Signature) input.Input
+		expect []string
+	}{
+		{
+			name: "pipe",
+			input: func(s types.Signature) input.Input {
+				return input.Psv(strings.NewReader("A|B\nfoo|bar\n"), s)
+			},
+			expect: []string{"bar"},
+		},
+		{
+			name: "tab",
+			input: func(s types.Signature) input.Input {
+				return input.Tsv(strings.NewReader("A\tB\nfoo\tbar baz\n"), s)
+			},
+			expect: []string{"bar baz"},
+		},
+		{
+			name: "tab with an empty field",
+			input: func(s types.Signature) input.Input {
+				return input.Tsv(strings.NewReader("A\tB\tC\nx\t\tz\ny\tw\tv\n"), s)
+			},
+			expect: []string{"", "w"},
+		},
+		{
+			name: "tab trimmed",
+			input: func(s types.Signature) input.Input {
+				return input.Tsv(strings.NewReader("A\tB\tC\nx\t\tz\ny\t w \tv\n"), s, input.TrimSpace(input.TrimBoth))
+			},
+			expect: []string{"", "w"},
+		},
+		{
+			name: "comment and trim",
+			input: func(s types.Signature) input.Input {
+				return input.Csv(strings.NewReader("# generated\nA;B\nfoo; bar  \n"), s,
+					input.Delimiter(';'),
+					input.Comment('#'),
+					input.TrimSpace(input.TrimBoth))
+			},
+			expect: []string{"bar"},
+		},
+		{
+			name: "variable fields and lazy quotes",
+			input: func(s types.Signature) input.Input {
+				return input.Csv(strings.NewReader("A,B\nfoo,ba\"r\nfoo,qux,extra\n"), s,
+					input.FieldsPerRecord(input.VariableFields),
+					input.LazyQuotes(true))
+			},
+			expect: []string{"ba\"r", "qux"},
+		},
+	}
+	for _, test := range table {
+		got := parseColumn(t, test.input(stringSchema(t, "A", "B")), "B")
+		if strings.Join(got, ",") != strings.Join(test.expect, ",") {
+			t.Errorf("%s: expected %v but got %v", test.name, test.expect, got)
+		}
+	}
+}
+
+func TestFixedWidth(t *testing.T) {
+	field, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: true})
+	amount, _ := types.NewField(pkg.FLOAT64, &pkg.Nullable{Allowed: true})
+	s := types.NewSchema(
+		types.Column("Loan ID", field, true, true),
+		types.Column("Amount", amount, true),
+		types.Span("Loan ID", 0, 6),
+		types.Span("Amount", 6, 10),
+	)
+	src := "L00001    100.50\r\n\nL00002     12.00\nL00003\n"
+	if got := parseColumn(t, input.FixedWidth(strings.NewReader(src), s), "Loan ID"); strings.Join(got, ",") != "L00001,L00002,L00003" {
+		t.Errorf("unexpected ids %v", got)
+	}
+	if got := parseColumn(t, input.FixedWidth(strings.NewReader(src), s), "Amount"); strings.Join(got, ",") != "100.5,12," {
+		t.Errorf("unexpected amounts %v", got)
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	field, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: true})
+	s := types.NewSchema(
+		types.Column("Loan ID", field, true),
+		types.Column("Borrower", field, true),
+		types.Column("State", field, false),
+		types.Alias("Loan ID", "loan_id"),
+		types.Alias("State", "address.state"),
+	)
+	src := `{"loan_id": 1001, "Borrower": "Ann", "address": {"state": "CA"}}
+{"Loan ID": "1002", "address": {"state": null}}
+
+{"Loan ID": "1003", "Borrower": {"first": "Bo"}}
+`
+	before := pkg.NewDC().Count()
+	if got := parseColumn(t, input.JSONLines(strings.NewReader(src), s), "Loan ID"); strings.Join(got, ",") != "1001,1002,1003" {
+		t.Errorf("unexpected ids %v", got)
+	}
+	if got := parseColumn(t, input.JSONLines(strings.NewReader(src), s), "Borrower"); strings.Join(got, "|") != `Ann||{"first":"Bo"}` {
+		t.Errorf("unexpected borrowers %v", got)
+	}
+	if got := parseColumn(t, input.JSONLines(strings.NewReader(src), s), "State"); strings.Join(got, ",") != "CA,," {
+		t.Errorf("unexpected states %v", got)
+	}
+	// one missing required key per parse of the second line
+	if d := pkg.NewDC().Count() - before; d != 3 {
+		t.Errorf("expected 3 defects but got %d", d)
+	}
+}
+
+func generateWorkbook(t testing.TB) *bytes.Buffer {
+	members := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Summary" sheetId="1" r:id="rId1"/><sheet name="Loans" sheetId="2" r:id="rId2"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>Loan ID</t></si><si><t>Funded</t></si><si><r><t>Amo</t></r><r><t>unt</t></r></si><si><t>L-1</t></si>
+</sst>`,
+		"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="1"><numFmt numFmtId="164" formatCode="[$-409]mmm\ d&quot;, &quot;yyyy"/></numFmts>
+<cellXfs count="3"><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="164"/></cellXfs>
+</styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData/></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="2"><c r="A2" t="s"><v>0</v></c><c r="B2" t="s"><v>1</v></c><c r="C2" t="s"><v>2</v></c></row>
+<row r="3"><c r="A3" t="s"><v>3</v></c><c r="B3" s="1"><v>43466</v></c><c r="C3"><v>1.5E+3</v></c></row>
+<row r="4"><c r="A4" t="inlineStr"><is><t>L-2</t></is></c><c r="B4" s="2"><v>43467.5</v></c></row>
+</sheetData></worksheet>`,
+	}
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for name, body := range members {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = f.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestXlsx(t *testing.T) {
+	field, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: true})
+	// the time of day is kept by a timestamp, a date is truncated
+	date, _ := types.NewField(pkg.TIMESTAMP, &pkg.Nullable{Allowed: true})
+	amount, _ := types.NewField(pkg.FLOAT64, &pkg.Nullable{Allowed: true})
+	s := types.NewSchema(
+		types.Column("Loan ID", field, true),
+		types.Column("Funded", date, true),
+		types.Column("Amount", amount, true),
+	)
+	table := []struct {
+		col    string
+		expect string
+	}{
+		{"Loan ID", "L-1,L-2"},
+		{"Funded", "2019-01-01T00:00:00Z,2019-01-02T12:00:00Z"},
+		{"Amount", "1500,"},
+	}
+	for _, test := range table {
+		in := input.Xlsx(generateWorkbook(t), "Loans", s)
+		p := parser.NewParser(&in)
+		header := uint32(1)
+		if err := p.Validate(&header); err != nil {
+			t.Fatal(err)
+		}
+		root, err := p.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := root.Find(test.col)
+		_, colIdx, _ := c.Id()
+		got := make([]string, 0)
+		for row := uint32(2); row <= 3; row++ {
+			got = append(got, *types.SimpleToString(c.FindById(pkg.GenNodeId(colIdx, row)).Value()))
+		}
+		if strings.Join(got, ",") != test.expect {
+			t.Errorf("%s: expected %s but got %v", test.col, test.expect, got)
+		}
+	}
+}
+
+// a user defined record reader, rows are semicolon separated strings
+type sliceReader struct {
+	rows []string
+}
+
+func (r *sliceReader) Read() ([]string, error) {
+	if len(r.rows) == 0 {
+		return nil, io.EOF
+	}
+	row := strings.Split(r.rows[0], ";")
+	r.rows = r.rows[1:]
+	return row, nil
+}
+
+func TestRecords(t *testing.T) {
+	s := stringSchema(t, "A", "B")
+	in := input.Records(&sliceReader{rows: []string{"title", "A;B", "1;2", "3;4"}}, s)
+	p := parser.NewParser(&in)
+	header := uint32(1)
+	if err := p.Validate(&header); err != nil {
+		t.Fatal(err)
+	}
+	if in.Line() != 2 {
+		t.Errorf("expected header on line 2 but got %d", in.Line())
+	}
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := root.Find("B"); c == nil || c.Max() != 3 {
+		t.Errorf("expected column B with rows up to 3")
+	}
+}
+
+func TestCompressed(t *testing.T) {
+	csvFactory := func(r io.Reader, def types.Signature) input.Input { return input.Csv(r, def) }
+	s := stringSchema(t, "A", "B")
+
+	gz := new(bytes.Buffer)
+	w := gzip.NewWriter(gz)
+	_, _ = w.Write([]byte("A,B\n1,2\n3,4\n"))
+	_ = w.Close()
+	if got := parseColumn(t, input.Compressed(gz, s, csvFactory), "A"); strings.Join(got, ",") != "1,3" {
+		t.Errorf("gzip: unexpected values %v", got)
+	}
+
+	archive := new(bytes.Buffer)
+	z := zip.NewWriter(archive)
+	for _, m := range []struct{ name, body string }{
+		{"2019-01-01.csv", "A,B\n1,2"},
+		{"readme.txt", "not a csv"},
+		{"2019-01-02.csv", "A,B\n3,4\n5,6\n"},
+	} {
+		f, _ := z.Create(m.name)
+		_, _ = f.Write([]byte(m.body))
+	}
+	_ = z.Close()
+	zipped := archive.Bytes()
+	if got := parseColumn(t, input.Compressed(bytes.NewReader(zipped), s, csvFactory, "*.csv"), "A"); strings.Join(got, ",") != "1,3,5" {
+		t.Errorf("zip: unexpected values %v", got)
+	}
+	// members are closed once read, a member not read to the end can be closed by the caller
+	streams, err := input.Decompress(bytes.NewReader(zipped))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range streams {
+		c, ok := m.Reader.(io.Closer)
+		if !ok {
+			t.Fatalf("%s: expected a closable member", m.Name)
+		}
+		if _, err = ioutil.ReadAll(m.Reader); err != nil {
+			t.Fatal(err)
+		}
+		if err = c.Close(); err != nil {
+			t.Errorf("%s: %v", m.Name, err)
+		}
+	}
+}
+
+func TestConcat(t *testing.T) {
+	csvFactory := func(r io.Reader, def types.Signature) input.Input { return input.Csv(r, def) }
+	field, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: true})
+	amount, _ := types.NewField(pkg.FLOAT64, &pkg.Nullable{Allowed: true})
+	s := types.NewSchema(
+		types.Column("A", field, true),
+		types.Column("B", amount, true),
+		types.Alias("B", "Amount"),
+	)
+	sources := func() []input.Source {
+		return []input.Source{
+			{Name: "day1.csv", Reader: strings.NewReader("A,B\n1,1.00\n2,2.00\n")},
+			{Name: "day2.csv", Reader: strings.NewReader("Amount,A\n3.00,3\nfoo,4\n")},
+		}
+	}
+	if got := parseColumn(t, input.Concat(s, csvFactory, sources()...), "A"); strings.Join(got, ",") != "1,2,3,4" {
+		t.Errorf("unexpected values %v", got)
+	}
+	before := pkg.NewDC().Count()
+	if got := parseColumn(t, input.Concat(s, csvFactory, sources()...), pkg.SourceColumn); strings.Join(got, ",") != "day1.csv,day1.csv,day2.csv,day2.csv" {
+		t.Errorf("unexpected sources %v", got)
+	}
+	defects := *pkg.NewDC().Coll()
+	if len(defects)-before != 1 {
+		t.Fatalf("expected one defect but got %d", len(defects)-before)
+	}
+	if d := defects[len(defects)-1]; d.Source != "day2.csv" || d.Line != 3 {
+		t.Errorf("expected defect at day2.csv:3 but got %s:%d", d.Source, d.Line)
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	utf16le := func(s string) string {
+		var b strings.Builder
+		b.WriteString("\xff\xfe")
+		for _, r := range s {
+			b.WriteByte(byte(r))
+			b.WriteByte(byte(r >> 8))
+		}
+		return b.String()
+	}
+	table := []struct {
+		name    string
+		src     string
+		opts    []input.Opt
+		expect  string
+		defects int
+	}{
+		{"utf-8 bom", "\xef\xbb\xbfA,B\nfoo,caf\xc3\xa9\n", nil, "café", 0},
+		{"utf-16le bom", utf16le("A,B\r\nfoo,café\r\n"), nil, "café", 0},
+		{"windows-1252", "A,B\nfoo,caf\xe9 \x80\n", nil, "café €", 0},
+		{"invalid utf-8", "A,B\nfoo,caf\xe9\n", []input.Opt{input.Charset(input.UTF8)}, "caf�", 1},
+	}
+	for _, test := range table {
+		before := pkg.NewDC().Count()
+		got := parseColumn(t, input.Csv(strings.NewReader(test.src), stringSchema(t, "A", "B"), test.opts...), "B")
+		if strings.Join(got, ",") != test.expect {
+			t.Errorf("%s: expected %s but got %v", test.name, test.expect, got)
+		}
+		if d := pkg.NewDC().Count() - before; d != test.defects {
+			t.Errorf("%s: expected %d defects but got %d", test.name, test.defects, d)
+		}
+	}
+
+	// the charset applies to fixed width and json lines input, csv options do not
+	text, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: true})
+	fixed := types.NewSchema(types.Column("B", text, true), types.Span("B", 0, 4))
+	if got := parseColumn(t, input.FixedWidth(strings.NewReader("caf\xe9\n"), fixed, input.Charset(input.UTF8)), "B"); strings.Join(got, ",") != "caf\ufffd" {
+		t.Errorf("fixed width: expected caf\ufffd but got %v", got)
+	}
+	lines := input.JSONLines(strings.NewReader("{\"B\": \"caf\xe9\"}\n"), stringSchema(t, "B"), input.Charset(input.Latin1))
+	if got := parseColumn(t, lines, "B"); strings.Join(got, ",") != "café" {
+		t.Errorf("json lines: expected café but got %v", got)
+	}
+	lines = input.JSONLines(strings.NewReader("{\"B\": 1}\n"), stringSchema(t, "B"), input.Delimiter(';'))
+	if err := parser.NewParser(&lines).Validate(nil); err == nil {
+		t.Error("expected an error for a delimiter on json lines input")
+	}
+}
+
+func TestMem(t *testing.T) {
+	nullable := &pkg.Nullable{Allowed: true, Variants: []string{"NULL"}}
+	id, _ := types.NewField(pkg.STRING, nullable)
+	amount, _ := types.NewField(pkg.FLOAT64, nullable)
+	mailed, _ := types.NewField(pkg.DATE, nullable)
+	s := types.NewSchema(
+		types.Column("Loan ID", id, true, true),
+		types.Column("Amount", amount, true),
+		types.Column("Mailed", mailed, false),
+		types.Alias("Mailed", "Dates.Mailed"))
+	type dates struct {
+		Mailed *time.Time
+	}
+	type loan struct {
+		Id     string `exttra:"Loan ID"`
+		Amount float64
+		Dates  dates
+	}
+	day := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	loans := []loan{{"1001", 250.25, dates{&day}}, {"1002", 10, dates{}}}
+	maps := []map[string]interface{}{
+		{"Loan ID": "1001", "Amount": 250.25, "Dates": map[string]interface{}{"Mailed": day}},
+		{"Loan ID": "1002", "Amount": "10"},
+	}
+	for _, rows := range []interface{}{loans, maps} {
+		in := input.Mem(rows, s)
+		p := parser.NewParser(&in)
+		if err := p.Validate(nil); err != nil {
+			t.Fatal(err)
+		}
+		root, err := p.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		type shape struct {
+			Id     string
+			Amount float64
+			Mailed time.Time
+		}
+		out := make([]interface{}, 0)
+		if err = output.Mem(root, shape{}, &out,
+			output.Alias("Loan ID", "Id"),
+			output.Alias("Amount", "Amount"),
+			output.Alias("Mailed", "Mailed")).Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if len(out) != 2 {
+			t.Fatalf("expected 2 rows but got %d", len(out))
+		}
+		got := map[string]shape{}
+		for _, v := range out {
+			got[v.(shape).Id] = v.(shape)
+		}
+		if got["1001"].Amount != 250.25 || !got["1001"].Mailed.Equal(day) {
+			t.Errorf("unexpected row %v", got["1001"])
+		}
+		if got["1002"].Amount != 10 || !got["1002"].Mailed.IsZero() {
+			t.Errorf("unexpected row %v", got["1002"])
+		}
+	}
+
+	// a named map type is read as a map
+	type row map[string]interface{}
+	if got := parseColumn(t, input.Mem([]row{{"Loan ID": "1001"}, {"Loan ID": "1002"}}, s), "Loan ID"); strings.Join(got, ",") != "1001,1002" {
+		t.Errorf("expected the rows of a named map type but got %v", got)
+	}
+
+	// a nil row is a defect, the rows after it are read
+	before := pkg.NewDC().Count()
+	if got := parseColumn(t, input.Mem([]*loan{&loans[0], nil, &loans[1]}, s), "Loan ID"); strings.Join(got, ",") != "1001,1002" {
+		t.Errorf("expected the rows around a nil row but got %v", got)
+	}
+	if defects := (*pkg.NewDC().Coll())[before:]; len(defects) != 1 || defects[0].Msg != "input/mem: nil row" || defects[0].Line != 2 {
+		t.Errorf("expected a nil row defect but got %+v", defects)
+	}
+
+	// typed times follow the date/time policy of the field
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	funded, _ := types.NewField(pkg.TIMESTAMP, nullable, types.SourceZone(la), types.TargetZone(time.UTC))
+	booked, _ := types.NewField(pkg.DATE, nullable, types.TargetZone(tokyo))
+	zoned := types.NewSchema(types.Column("Funded", funded, true), types.Column("Booked", booked, true))
+	in := input.Mem([]map[string]interface{}{
+		{"Funded": time.Date(2019, 1, 1, 10, 0, 0, 0, time.UTC), "Booked": time.Date(2019, 1, 1, 20, 0, 0, 0, time.UTC)},
+	}, zoned)
+	p := parser.NewParser(&in)
+	if err = p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, expect := range map[string]time.Time{
+		"Funded": time.Date(2019, 1, 1, 18, 0, 0, 0, time.UTC),
+		"Booked": time.Date(2019, 1, 2, 0, 0, 0, 0, tokyo),
+	} {
+		col := root.Find(name)
+		_, ci, _ := col.Id()
+		if got := col.FindById(pkg.GenNodeId(ci, 1)).Value().(time.Time); !got.Equal(expect) || got.Location() != expect.Location() {
+			t.Errorf("%s: expected %v but got %v", name, expect, got)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+func TestLocale(t *testing.T) {
+	percent := types.NumberFormat{Decimal: '.', Grouping: ',', Percent: true}
+	euro := types.NumberFormat{Decimal: ',', Grouping: '.', Currencies: []string{"EUR", "€"}}
+	table := []struct {
+		t      pkg.FieldType
+		nf     types.NumberFormat
+		in     string
+		expect string
+	}{
+		{pkg.FLOAT64, types.DeDE, "1.234,56", "1234.56"},
+		{pkg.FLOAT64, types.DeDE, "(1.234,56)", "-1234.56"},
+		{pkg.FLOAT64, types.DeDE, "1.234,56-", "-1234.56"},
+		{pkg.FLOAT64, types.FrFR, "1 234,5", "1234.5"},
+		{pkg.FLOAT64, types.DeCH, "CHF 1'234.50", "1234.5"},
+		{pkg.FLOAT64, types.EnUS, "-$1,200.50", "-1200.5"},
+		{pkg.FLOAT64, types.EnUS, "12.5%", "12.5"},
+		{pkg.FLOAT64, percent, "12.5%", "0.125"},
+		{pkg.DECIMAL, percent, "(3.25 %)", "-0.03"},
+		{pkg.DECIMAL, euro, "1.234,565 €", "1234.57"},
+		{pkg.DECIMAL, euro, "EUR -12", "-12.00"},
+		{pkg.INT64, types.DeDE, "1.250,00", "1250"},
+		{pkg.INT32, percent, "1,200%", "12"},
+		{pkg.UINT16, types.EnUS, "$1,024", "1024"},
+	}
+	for _, test := range table {
+		field, err := types.NewField(test.t, &pkg.Nullable{Allowed: false}, types.Locale(test.nf))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _, err := field.Convert(&test.in)
+		if err != nil {
+			t.Errorf("%s %s: %v", test.t, test.in, err)
+			continue
+		}
+		if got := *types.SimpleToString(v); got != test.expect {
+			t.Errorf("%s %s: expected %s but got %s", test.t, test.in, test.expect, got)
+		}
+	}
+	for _, test := range []struct {
+		t  pkg.FieldType
+		nf types.NumberFormat
+		in string
+	}{
+		{pkg.FLOAT64, types.DeDE, "1,234,56"},
+		{pkg.FLOAT64, types.DeDE, "12 apples"},
+		{pkg.FLOAT64, types.DeDE, "(-12)"},
+		{pkg.DECIMAL, euro, "$12"},
+		{pkg.DECIMAL, euro, "USD 12"},
+		{pkg.INT64, types.DeDE, "12,5"},
+		{pkg.UINT8, types.EnUS, "(12)"},
+		// en-US values misread with DeDE
+		{pkg.FLOAT64, types.DeDE, "1.5"},
+		{pkg.FLOAT64, types.DeDE, "12.34.5"},
+		{pkg.DECIMAL, types.DeDE, "1.234.56"},
+		{pkg.FLOAT64, types.FrFR, "1 2,5"},
+		{pkg.INT64, types.EnUS, "1,00"},
+	} {
+		field, _ := types.NewField(test.t, &pkg.Nullable{Allowed: false}, types.Locale(test.nf))
+		if v, _, err := field.Convert(&test.in); err == nil {
+			t.Errorf("%s %s: expected an error but got %v", test.t, test.in, v)
+		}
+	}
+	if _, err := types.NewField(pkg.STRING, &pkg.Nullable{}, types.Locale(types.DeDE)); err == nil {
+		t.Error("expected an error setting a locale on a string field")
+	}
+
+	// without a locale a negative in parentheses stays negative
+	for _, ft := range []pkg.FieldType{pkg.INT64, pkg.FLOAT64} {
+		field, _ := types.NewField(ft, &pkg.Nullable{Allowed: false})
+		in := "($1,200)"
+		if v, _, err := field.Convert(&in); err != nil || fmt.Sprint(v) != "-1200" {
+			t.Errorf("%s: expected -1200 but got %v %v", ft, v, err)
+		}
+	}
+
+	// the locale is kept in a schema file
+	field, _ := types.NewField(pkg.DECIMAL, &pkg.Nullable{Allowed: false}, types.Locale(euro), types.Precision(10, 3))
+	var buf bytes.Buffer
+	if err := types.WriteSchema(&buf, types.NewSchema(types.Column("Betrag", field, true)), types.YAML); err != nil {
+		t.Fatal(err)
+	}
+	def, err := types.ReadSchema(&buf, types.YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := def.(*types.Schema).Cols()[0].Field
+	if nf := read.Locale(); nf == nil || nf.Decimal != ',' || nf.Grouping != '.' || strings.Join(nf.Currencies, ",") != "EUR,€" {
+		t.Errorf("unexpected locale %+v", nf)
+	}
+	in := "€ 1.234,5678"
+	if v, _, err := read.Convert(&in); err != nil || v.(pkg.Decimal).String() != "1234.568" {
+		t.Errorf("expected 1234.568 but got %v %v", v, err)
+	}
+}
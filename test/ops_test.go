@@ -0,0 +1,46 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/loanpal-engineering/exttra/data"
+	"github.com/loanpal-engineering/exttra/io/input"
+	"github.com/loanpal-engineering/exttra/parser"
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+func TestSizedCompare(t *testing.T) {
+	for _, ft := range []pkg.FieldType{pkg.INT8, pkg.INT16, pkg.INT32, pkg.UINT8, pkg.UINT16, pkg.UINT32, pkg.DECIMAL} {
+		field, err := types.NewField(ft, &pkg.Nullable{Allowed: false})
+		if err != nil {
+			t.Fatal(err)
+		}
+		in := input.Csv(strings.NewReader("Term\n12\n36\n"), types.NewSchema(types.Column("Term", field, true)))
+		p := parser.NewParser(&in)
+		if err = p.Validate(nil); err != nil {
+			t.Fatal(err)
+		}
+		root, err := p.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		term := root.Find("Term")
+		_, ci, _ := term.Id()
+		// compare each term to the first
+		rhs, _ := data.NewNode(nil, data.V(term.FindById(pkg.GenNodeId(ci, 1)).Value()), data.Type(&ft))
+		for _, op := range []pkg.Operator{pkg.Lt{Lhs: term, Rhs: rhs}, pkg.Gt{Lhs: term, Rhs: rhs}, pkg.Eq{Lhs: term, Rhs: rhs}} {
+			res, _, err := pkg.Evaluate(context.Background(), op)
+			if err != nil {
+				t.Fatalf("%s: %v", ft, err)
+			}
+			_, isLt := op.(pkg.Lt)
+			_, isGt := op.(pkg.Gt)
+			if res[1] != (!isLt && !isGt) || res[2] != isGt {
+				t.Errorf("%s %T: unexpected result %v", ft, op, res)
+			}
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/loanpal-engineering/exttra/io/input"
+	"github.com/loanpal-engineering/exttra/io/output"
+	"github.com/loanpal-engineering/exttra/parser"
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+func TestReportLayout(t *testing.T) {
+	report := strings.Join([]string{
+		"Monthly filing report,,",
+		"Generated 2019-01-31,,",
+		",UCC,",
+		"Loan ID,mailed,recorded",
+		"1,2019-01-01,2019-01-05",
+		",,",
+		"2,2019-01-02,",
+		"TOTAL,Record count: 2,",
+		"3,2019-01-03,",
+	}, "\n")
+	table := []struct {
+		name   string
+		schema types.Signature
+		col    string
+		opts   []parser.Opt
+		expect string
+	}{
+		{
+			name:   "skip and merge header",
+			schema: stringSchema(t, "Loan ID", "UCC mailed", "UCC recorded"),
+			col:    "UCC recorded",
+			opts: []parser.Opt{parser.Skip(2), parser.HeaderRows(2), parser.SkipBlankRows(),
+				parser.Trailer(parser.Prefix("total"), parser.CountAt(1))},
+			expect: "2019-01-05,",
+		},
+		{
+			name:   "detect header",
+			schema: stringSchema(t, "Loan ID"),
+			col:    "Loan ID",
+			opts: []parser.Opt{parser.DetectHeader(5), parser.SkipBlankRows(),
+				parser.Trailer(parser.Prefix("total"), nil)},
+			expect: "1,2",
+		},
+		{
+			name:   "stop at blank row",
+			schema: stringSchema(t, "Loan ID"),
+			col:    "Loan ID",
+			opts:   []parser.Opt{parser.DetectHeader(5)},
+			expect: "1",
+		},
+	}
+	for _, test := range table {
+		in := input.Csv(strings.NewReader(report), test.schema, input.FieldsPerRecord(input.VariableFields))
+		if got := parseColumn(t, in, test.col, test.opts...); strings.Join(got, ",") != test.expect {
+			t.Errorf("%s: expected %s but got %v", test.name, test.expect, got)
+		}
+	}
+
+	report = strings.Replace(report, "Record count: 2", "Record count: 3", 1)
+	in := input.Csv(strings.NewReader(report), stringSchema(t, "Loan ID"), input.FieldsPerRecord(input.VariableFields))
+	p := parser.NewParser(&in, parser.DetectHeader(5), parser.SkipBlankRows(),
+		parser.Trailer(parser.Prefix("total"), parser.CountAt(1)))
+	if err := p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Parse(); err == nil {
+		t.Error("expected a record count mismatch")
+	}
+}
+
+func TestPositional(t *testing.T) {
+	src := "1001,Ann,2019-01-01\n1002,Bo\n1003,Cy,2019-01-03\n"
+	field, err := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := types.NewSchema(
+		types.Column("Loan ID", field, true),
+		types.Column("mailed", field, false),
+		types.Position("mailed", 2))
+	before := pkg.NewDC().Count()
+	in := input.Csv(strings.NewReader(src), s, input.FieldsPerRecord(input.VariableFields))
+	p := parser.NewParser(&in)
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for col, expect := range map[string]string{"Loan ID": "1001,1002,1003", "mailed": "2019-01-01,,2019-01-03"} {
+		c := root.Find(col)
+		if c == nil {
+			t.Fatalf("column %s not found", col)
+		}
+		_, ci, _ := c.Id()
+		got := make([]string, 0)
+		for ri := uint32(1); ri <= 3; ri++ {
+			v := ""
+			if n := c.FindById(pkg.GenNodeId(ci, ri)); n != nil && n.Value() != nil {
+				v = n.Value().(string)
+			}
+			got = append(got, v)
+		}
+		if strings.Join(got, ",") != expect {
+			t.Errorf("%s: expected %s but got %v", col, expect, got)
+		}
+	}
+	// the short second record
+	if d := pkg.NewDC().Count() - before; d != 1 {
+		t.Errorf("expected 1 defect but got %d", d)
+	}
+
+	in = input.Csv(strings.NewReader("x,Ann\n"+src), s, input.FieldsPerRecord(input.VariableFields))
+	if got := parseColumn(t, in, "Loan ID", parser.Skip(1)); strings.Join(got, ",") != "1001,1002,1003" {
+		t.Errorf("expected skipped record but got %v", got)
+	}
+}
+
+func TestStream(t *testing.T) {
+	rows := []string{"Loan ID,Amount"}
+	for _, id := range []string{"1", "2", "3", "4", "2", "6", "7"} {
+		rows = append(rows, id+","+id+"00")
+	}
+	src := strings.Join(rows, "\n")
+	id, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: false})
+	amount, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: true})
+	def := types.NewSchema(
+		types.Column("Loan ID", id, true, true),
+		types.Column("Amount", amount, true))
+
+	in := input.Csv(strings.NewReader(src), def)
+	p := parser.NewParser(&in)
+	if err := p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	before := pkg.NewDC().Count()
+	got := make([]string, 0)
+	offsets := make([]uint32, 0)
+	err := p.Stream(3, func(b parser.Batch) error {
+		offsets = append(offsets, b.Offset)
+		buf := new(bytes.Buffer)
+		if err := output.Csv(b.Root, buf).Flush(); err != nil {
+			return err
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		got = append(got, strings.Join(lines[1:], ";"))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the duplicate id 2 in the second batch is excluded
+	if expect := "1,100;2,200;3,300|4,400;6,600|7,700"; strings.Join(got, "|") != expect {
+		t.Errorf("expected %s but got %s", expect, strings.Join(got, "|"))
+	}
+	if fmt.Sprint(offsets) != "[0 3 6]" {
+		t.Errorf("unexpected offsets %v", offsets)
+	}
+	defects := *pkg.NewDC().Coll()
+	if len(defects)-before != 1 {
+		t.Fatalf("expected one duplicate defect but got %d", len(defects)-before)
+	}
+	if d := defects[len(defects)-1]; d.Row != 5 || d.Keys["Loan ID"] != "2" {
+		t.Errorf("expected duplicate on row 5 keyed 2 but got %d %v", d.Row, d.Keys)
+	}
+
+	in = input.Csv(strings.NewReader(src), def)
+	p = parser.NewParser(&in)
+	if err = p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	batches, errs := p.Batches(4)
+	count := 0
+	for b := range batches {
+		count += int(b.Root.Find("Amount").Max())
+	}
+	if err = <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if count != 7 {
+		t.Errorf("expected 7 rows but got %d", count)
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	rows := []string{"Loan ID,Mailed,Fee"}
+	for ii := 1; ii <= 500; ii++ {
+		mailed, fee := fmt.Sprintf("2019-01-%02d", ii%28+1), fmt.Sprintf("%d.50", ii)
+		if ii%7 == 0 {
+			mailed = "not a date"
+		}
+		if ii%11 == 0 {
+			fee = "n/a"
+		}
+		rows = append(rows, fmt.Sprintf("%d,%s,%s", ii%450, mailed, fee))
+	}
+	src := strings.Join(rows, "\n")
+	id, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: false})
+	mailed, _ := types.NewField(pkg.DATE, &pkg.Nullable{Allowed: true})
+	fee, _ := types.NewField(pkg.FLOAT64, &pkg.Nullable{Allowed: true})
+	def := types.NewSchema(
+		types.Column("Loan ID", id, true, true),
+		types.Column("Mailed", mailed, true),
+		types.Column("Fee", fee, true))
+
+	run := func(workers int) (string, []string) {
+		in := input.Csv(strings.NewReader(src), def)
+		p := parser.NewParser(&in, parser.Workers(workers))
+		if err := p.Validate(nil); err != nil {
+			t.Fatal(err)
+		}
+		before := pkg.NewDC().Count()
+		root, err := p.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// columns are written in no particular order, compare the tree
+		var buf strings.Builder
+		excludes := root.(pkg.Editor).Excludes()
+		for _, name := range []string{"Loan ID", "Mailed", "Fee"} {
+			col := root.Find(name)
+			_, ci, _ := col.Id()
+			for ri := uint32(1); ri <= uint32(col.Max()); ri++ {
+				if n := col.FindById(pkg.GenNodeId(ci, ri)); n != nil {
+					fmt.Fprintf(&buf, "%d:%v:%v;", ri, n.Value(), excludes[ri])
+				}
+			}
+		}
+		defects := make([]string, 0)
+		for _, d := range (*pkg.NewDC().Coll())[before:] {
+			defects = append(defects, fmt.Sprintf("%d:%d:%s:%v", d.Row, d.Col, d.Msg, d.Keys))
+		}
+		return buf.String(), defects
+	}
+	out, defects := run(1)
+	if len(defects) == 0 {
+		t.Fatal("expected defects")
+	}
+	for _, workers := range []int{2, 8} {
+		o, d := run(workers)
+		if o != out {
+			t.Errorf("%d workers: output differs from a single worker", workers)
+		}
+		if strings.Join(d, "\n") != strings.Join(defects, "\n") {
+			t.Errorf("%d workers: defects differ from a single worker", workers)
+		}
+	}
+
+	// rows read before a failed trailer check are converted
+	src += "\nTOTAL,0,"
+	for _, workers := range []int{1, 4} {
+		in := input.Csv(strings.NewReader(src), def)
+		p := parser.NewParser(&in, parser.Workers(workers), parser.Trailer(parser.Prefix("total"), parser.CountAt(1)))
+		if err := p.Validate(nil); err != nil {
+			t.Fatal(err)
+		}
+		before := pkg.NewDC().Count()
+		if _, err := p.Parse(); err == nil {
+			t.Errorf("%d workers: expected a record count mismatch", workers)
+		}
+		if d := pkg.NewDC().Count() - before; d != len(defects) {
+			t.Errorf("%d workers: expected %d defects but got %d", workers, len(defects), d)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	src := strings.Join([]string{
+		"Loan ID,Filing Type,Amount",
+		"1001,UCC1,10",
+		"1001,UCC3,20",
+		"1001,UCC1,30",
+		"1002,UCC1,40",
+		"1002, UCC1,50",
+		"1002,UCC1,60",
+		"1003,UCC1,abc",
+	}, "\n")
+	id, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: false})
+	filing, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: false})
+	amount, _ := types.NewField(pkg.FLOAT64, &pkg.Nullable{Allowed: true})
+	tests := []struct {
+		policy  types.KeyPolicy
+		kept    string
+		defects int
+	}{
+		{types.KeepFirst, "10,20,40,", 3},
+		{types.KeepLast, "20,30,60,", 3},
+		{types.RejectAll, "20,", 5},
+		{types.DefectOnly, "10,20,30,40,50,60,", 3},
+	}
+	for _, test := range tests {
+		def := types.NewSchema(
+			types.Column("Loan ID", id, true),
+			types.Column("Filing Type", filing, true),
+			types.Column("Amount", amount, true),
+			types.Key("filing", test.policy, "Loan ID", "Filing Type"))
+		in := input.Csv(strings.NewReader(src), def)
+		p := parser.NewParser(&in)
+		if err := p.Validate(nil); err != nil {
+			t.Fatal(err)
+		}
+		before := pkg.NewDC().Count()
+		root, err := p.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		excludes := root.(pkg.Editor).Excludes()
+		col := root.Find("Amount")
+		_, ci, _ := col.Id()
+		kept := make([]string, 0)
+		for ri := uint32(1); ri <= uint32(col.Max()); ri++ {
+			if excludes[ri] {
+				continue
+			}
+			if v := col.FindById(pkg.GenNodeId(ci, ri)).Value(); v != nil {
+				kept = append(kept, *types.SimpleToString(v))
+			} else {
+				kept = append(kept, "")
+			}
+		}
+		if got := strings.Join(kept, ","); got != test.kept {
+			t.Errorf("%s: expected rows %s but got %s", test.policy, test.kept, got)
+		}
+		defects := (*pkg.NewDC().Coll())[before:]
+		// duplicates, and the conversion error of the last row
+		if len(defects) != test.defects+1 {
+			t.Errorf("%s: expected %d defects but got %d", test.policy, test.defects+1, len(defects))
+		}
+		for _, d := range defects {
+			if d.Keys["Loan ID"] == "" || strings.TrimSpace(d.Keys["Filing Type"]) != "UCC1" {
+				t.Errorf("%s: expected the key values on defect %+v", test.policy, d)
+			}
+			if d.Row < 7 && !strings.HasPrefix(d.Msg, "Duplicate key filing [") {
+				t.Errorf("%s: unexpected defect %+v", test.policy, d)
+			}
+		}
+		if test.policy == types.KeepLast {
+			// the defect is on the replaced row
+			rows := make([]string, 0)
+			for _, d := range defects[:test.defects] {
+				rows = append(rows, fmt.Sprintf("%d:%d", d.Row, d.Line))
+			}
+			if got := strings.Join(rows, ","); got != "1:2,4:5,5:6" {
+				t.Errorf("%s: expected defects on rows 1:2,4:5,5:6 but got %s", test.policy, got)
+			}
+		}
+	}
+
+	// rows are excluded when every key column is nullable
+	optional, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: true})
+	for policy, expect := range map[types.KeyPolicy]string{
+		types.KeepFirst: "false,false,true,false",
+		types.KeepLast:  "false,true,false,false",
+		types.RejectAll: "false,true,true,false",
+	} {
+		def := types.NewSchema(
+			types.Column("A", optional, true),
+			types.Column("B", optional, true),
+			types.Key("ab", policy, "A", "B"))
+		in := input.Csv(strings.NewReader("A,B\n1,x\n1,x\n2,y\n"), def)
+		p := parser.NewParser(&in)
+		if err := p.Validate(nil); err != nil {
+			t.Fatal(err)
+		}
+		root, err := p.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, 0)
+		for _, ex := range root.(pkg.Editor).Excludes() {
+			got = append(got, fmt.Sprint(ex))
+		}
+		if strings.Join(got, ",") != expect {
+			t.Errorf("%s: expected excludes %s but got %v", policy, expect, got)
+		}
+	}
+
+	// a nullable unique column hides its own cell of a duplicate row, the row is kept
+	def := types.NewSchema(
+		types.Column("Loan ID", optional, true, true),
+		types.Column("Filing Type", filing, true))
+	in := input.Csv(strings.NewReader("Loan ID,Filing Type\n1001,UCC1\n1001,UCC3\n"), def)
+	p := parser.NewParser(&in)
+	if err := p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	col := root.Find("Loan ID")
+	_, ci, _ := col.Id()
+	hidden, err := col.Excluded(pkg.GenNodeId(ci, 2))
+	if err != nil || !hidden || root.(pkg.Editor).Excludes()[2] {
+		t.Errorf("expected the duplicate id to be hidden and its row kept")
+	}
+
+	var se *pkg.SchemaException
+	if err := types.NewSchema(types.Column("Loan ID", id, true), types.Key("filing", types.KeepFirst, "Loan ID", "Filing Type")).(*types.Schema).Err(); !errors.As(err, &se) || se.Column != "Filing Type" {
+		t.Errorf("expected a SchemaException for an unknown key column but got %v", err)
+	}
+}
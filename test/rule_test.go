@@ -0,0 +1,184 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loanpal-engineering/exttra/io/input"
+	"github.com/loanpal-engineering/exttra/io/output"
+	"github.com/loanpal-engineering/exttra/parser"
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+func TestRules(t *testing.T) {
+	since := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	id, err := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: false}, types.Pattern(`^[0-9]{4}$`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: true}, types.NotBlank(), types.MaxLength(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount, err := types.NewField(pkg.DECIMAL, &pkg.Nullable{Allowed: true}, types.Min(0), types.Max(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	funded, err := types.NewField(pkg.DATE, &pkg.Nullable{Allowed: true}, types.Earliest(since), types.NotFuture())
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := types.NewSchema(
+		types.Column("Loan ID", id, true),
+		types.Column("Name", name, true),
+		types.Column("Amount", amount, true),
+		types.Column("Funded", funded, true))
+	src := strings.Join([]string{
+		"Loan ID,Name,Amount,Funded",
+		"1001,Ann,100,2019-01-01",
+		"100A,Bo,1,2019-01-01",
+		"1003,,5,2019-01-01",
+		"1004,Alexandra,5,2019-01-01",
+		"1005,Cy,-1,2019-01-01",
+		"1006,Di,1000.01,2019-01-01",
+		"1007,Ed,5,1999-12-31",
+		"1008,Flo,5,2999-01-01",
+		"1009,  ,5,2019-01-01",
+	}, "\n") + "\n"
+	in := input.Csv(strings.NewReader(src), def)
+	p := parser.NewParser(&in)
+	if err = p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	before := pkg.NewDC().Count()
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []struct {
+		row, col int
+		rule     string
+	}{
+		{2, 0, types.RulePattern},
+		{3, 1, types.RuleNotBlank},
+		{4, 1, types.RuleMaxLength},
+		{5, 2, types.RuleMin},
+		{6, 2, types.RuleMax},
+		{7, 3, types.RuleEarliest},
+		{8, 3, types.RuleNotFuture},
+		{9, 1, types.RuleNotBlank},
+	}
+	defects := (*pkg.NewDC().Coll())[before:]
+	if len(defects) != len(expect) {
+		t.Fatalf("expected %d defects but got %+v", len(expect), defects)
+	}
+	for ii, e := range expect {
+		if d := defects[ii]; d.Row != e.row || d.Col != e.col || d.Rule != e.rule {
+			t.Errorf("expected rule %s at %d,%d but got %s at %d,%d: %s", e.rule, e.row, e.col, d.Rule, d.Row, d.Col, d.Msg)
+		}
+	}
+
+	// a value breaking a rule is hidden, the row is excluded when the column is not nullable
+	var buf bytes.Buffer
+	if err = output.Csv(root, &buf).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	out := strings.Join([]string{
+		"Loan ID,Name,Amount,Funded",
+		"1001,Ann,100.00,2019-01-01",
+		"1003,,5.00,2019-01-01",
+		"1004,,5.00,2019-01-01",
+		"1005,Cy,,2019-01-01",
+		"1006,Di,,2019-01-01",
+		"1007,Ed,5.00,",
+		"1008,Flo,5.00,",
+		"1009,,5.00,2019-01-01",
+	}, "\n") + "\n"
+	if buf.String() != out {
+		t.Errorf("expected\n%s\nbut got\n%s", out, buf.String())
+	}
+
+	report := pkg.NewDC().(*pkg.Defects).Report(0)
+	column := -1
+	for ii, h := range report[0] {
+		if h == "Rule" {
+			column = ii
+		}
+	}
+	if column < 0 || report[len(report)-1][column] != types.RuleNotBlank {
+		t.Errorf("expected a rule column in the report but got %v", report[0])
+	}
+
+	// the rules are kept in a schema file
+	buf.Reset()
+	if err = types.WriteSchema(&buf, def, types.YAML); err != nil {
+		t.Fatal(err)
+	}
+	again, err := types.ReadSchema(&buf, types.YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cols := again.(*types.Schema).Cols()
+	for _, test := range []struct {
+		col  int
+		in   string
+		rule string
+	}{
+		{0, "12345", types.RulePattern},
+		{1, "Alexandra", types.RuleMaxLength},
+		{2, "-1", types.RuleMin},
+		{3, "1999-12-31", types.RuleEarliest},
+		{3, "2999-01-01", types.RuleNotFuture},
+	} {
+		field := cols[test.col].Field
+		v, _, err := field.Convert(&test.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rule, _ := field.Check(&test.in, v); rule != test.rule {
+			t.Errorf("%s: expected rule %s but got %s", test.in, test.rule, rule)
+		}
+	}
+	written := "columns:\n  - name: Funded\n    type: DATE\n    nullable: {allowed: true}\n    rules: {earliest: 2000-01-01, notFuture: true}\n"
+	if again, err = types.ReadSchema(strings.NewReader(written), types.YAML); err != nil {
+		t.Fatal(err)
+	}
+	field := again.(*types.Schema).Cols()[0].Field
+	v, _, _ := field.Convert(&[]string{"1999-12-31"}[0])
+	if rule, _ := field.Check(nil, v); rule != types.RuleEarliest {
+		t.Errorf("expected rule %s but got %s", types.RuleEarliest, rule)
+	}
+
+	// a decimal is compared to its bounds exactly
+	exact, err := types.NewField(pkg.DECIMAL, &pkg.Nullable{}, types.Precision(18, 17), types.Min(0.1), types.Max(0.3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for in, expect := range map[string]string{
+		"0.30000000000000001": types.RuleMax,
+		"0.3":                 "",
+		"0.1":                 "",
+		"0.09999999999999999": types.RuleMin,
+	} {
+		v, _, err := exact.Convert(&in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rule, _ := exact.Check(&in, v); rule != expect {
+			t.Errorf("%s: expected rule %q but got %q", in, expect, rule)
+		}
+	}
+
+	if _, err = types.NewField(pkg.INT64, &pkg.Nullable{}, types.MaxLength(5)); err == nil {
+		t.Error("expected an error setting a length on an integer field")
+	}
+	if _, err = types.NewField(pkg.STRING, &pkg.Nullable{}, types.Pattern("[")); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+	if _, err = types.NewField(pkg.FLOAT64, &pkg.Nullable{}, types.Min(10), types.Max(1)); err == nil {
+		t.Error("expected an error for a minimum greater than the maximum")
+	}
+}
@@ -0,0 +1,332 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loanpal-engineering/exttra/io/input"
+	"github.com/loanpal-engineering/exttra/io/output"
+	"github.com/loanpal-engineering/exttra/parser"
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+func TestInfer(t *testing.T) {
+	src := strings.Join([]string{
+		"Loan ID,Name,Amount,Mailed,Active,Created,Code",
+		"1001,Ann,\"$1,200.50\",2019-01-01,yes,2019-01-01 10:30:00,A1",
+		"1002,Bo,300,NULL,no,2019-01-02 11:00:00,17",
+		"1003,Cy,12.5,2019-01-03,yes,2019-01-03 12:15:00,18",
+		",,,,,,",
+		"1004,Di,7,,no,2019-01-04 08:00:00,19",
+	}, "\n")
+	def, report, err := input.Infer(input.Csv(strings.NewReader(src), types.NewSchema()), 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows != 4 {
+		t.Errorf("expected a sample of 4 rows but got %d", report.Rows)
+	}
+	expect := map[string]pkg.FieldType{
+		"Loan ID": pkg.INT64,
+		"Name":    pkg.STRING,
+		"Amount":  pkg.FLOAT64,
+		"Mailed":  pkg.DATE,
+		"Active":  pkg.BOOL,
+		"Created": pkg.TIMESTAMP,
+		"Code":    pkg.STRING,
+	}
+	if len(def.Cols()) != len(expect) {
+		t.Fatalf("expected %d columns but got %d", len(expect), len(def.Cols()))
+	}
+	for _, c := range def.Cols() {
+		if c.Field.T != expect[c.Name] {
+			t.Errorf("%s: expected %s but got %s", c.Name, expect[c.Name], c.Field.T)
+		}
+		if !c.Required {
+			t.Errorf("%s: expected an inferred column to be required", c.Name)
+		}
+	}
+	for _, c := range report.Columns {
+		switch c.Name {
+		case "Loan ID":
+			if !c.Unique || c.Nulls != 0 || c.Confidence != 1 {
+				t.Errorf("Loan ID: unexpected report %+v", c)
+			}
+		case "Mailed":
+			if c.Nulls != 2 || len(c.Variants) != 1 || c.Variants[0] != "NULL" {
+				t.Errorf("Mailed: unexpected report %+v", c)
+			}
+		case "Active":
+			if c.Unique {
+				t.Error("Active: a boolean column is not unique")
+			}
+		}
+	}
+
+	// with a lower confidence the code column is an integer column, the rejected value is reported
+	_, report, err = input.Infer(input.Csv(strings.NewReader(src), types.NewSchema()), 0, 100, types.Confidence(0.6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := report.Columns[6]
+	if code.T != pkg.INT64 || code.Confidence != 0.75 || len(code.Rejects) != 1 || code.Rejects[0] != "A1" {
+		t.Errorf("Code: unexpected report %+v", code)
+	}
+	if !strings.Contains(report.String(), "Code") {
+		t.Errorf("expected the report table to list Code:\n%s", report)
+	}
+
+	var buf bytes.Buffer
+	if err = report.GoSource(&buf, "vendor", "Schema"); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"package vendor",
+		"func Schema() (types.Signature, error) {",
+		`loanId, err := types.NewField(pkg.INT64, &pkg.Nullable{Allowed: false})`,
+		`mailed, err := types.NewField(pkg.DATE, &pkg.Nullable{Allowed: true, Variants: []string{"NULL"}})`,
+		`types.Column("Loan ID", loanId, true, true),`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected the Go source to contain %s:\n%s", s, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err = report.SchemaFile(&buf, types.JSON); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Columns []struct {
+			Name string
+			Type string
+		}
+	}
+	if err = json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Columns) != 7 || doc.Columns[0].Name != "Loan ID" || doc.Columns[0].Type != "INT64" {
+		t.Errorf("unexpected schema file:\n%s", buf.String())
+	}
+
+	// the inferred schema parses the file
+	if got := parseColumn(t, input.Csv(strings.NewReader(src), def), "Amount"); strings.Join(got, ",") != "1200.5,300,12.5" {
+		t.Errorf("expected amounts 1200.5,300,12.5 but got %v", got)
+	}
+}
+
+func TestSchemaFile(t *testing.T) {
+	types.RegisterConverter("cents", func(in *string, _ ...interface{}) (interface{}, error) {
+		f, err := strconv.ParseFloat(strings.TrimPrefix(*in, "$"), 64)
+		// custom fields hold strings
+		return strconv.FormatInt(int64(math.Round(f*100)), 10), err
+	})
+	types.RegisterStringify("cents", func(it interface{}) *string {
+		s := fmt.Sprint(it)
+		return &s
+	})
+	doc := strings.Join([]string{
+		"columns:",
+		"  - name: Loan ID",
+		"    type: STRING",
+		"    required: true",
+		"    unique: true",
+		"    aliases: [LoanNumber]",
+		"    nullable: {allowed: false}",
+		"  - name: Amount",
+		"    type: CUSTOM",
+		"    converter: cents",
+		"    stringify: cents",
+		"    nullable: {allowed: true, variants: [NULL], replaceWith: \"0\"}",
+		"indices: [Loan ID]",
+	}, "\n")
+	def, err := types.ReadSchema(strings.NewReader(doc), types.YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := def.(*types.Schema)
+	if len(s.Cols()) != 2 || !s.Indexed("Loan ID") {
+		t.Fatalf("unexpected schema %+v", s.Cols())
+	}
+	if c := s.Cols()[0]; !c.Required || !c.Unique || len(c.Aliases) != 1 || c.Aliases[0] != "LoanNumber" {
+		t.Errorf("unexpected Loan ID definition %+v", c)
+	}
+	src := "LoanNumber,Amount\n1001,$12.34\n1002,NULL\n"
+	if got := parseColumn(t, input.Csv(strings.NewReader(src), def), "Amount"); strings.Join(got, ",") != "1234," {
+		t.Errorf("expected 1234, but got %v", got)
+	}
+
+	// a schema written to a file reads back to the same schema
+	var yml, js bytes.Buffer
+	if err = types.WriteSchema(&yml, def, types.YAML); err != nil {
+		t.Fatal(err)
+	}
+	if err = types.WriteSchema(&js, def, types.JSON); err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := types.ReadSchema(&js, types.JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again bytes.Buffer
+	if err = types.WriteSchema(&again, fromJSON, types.YAML); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != yml.String() {
+		t.Errorf("expected\n%s\nbut got\n%s", yml.String(), again.String())
+	}
+	path := filepath.Join(t.TempDir(), "schema.yml")
+	if err = ioutil.WriteFile(path, yml.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = types.LoadSchema(path); err != nil {
+		t.Fatal(err)
+	}
+
+	var se *pkg.SchemaException
+	_, err = types.ReadSchema(strings.NewReader("columns:\n  - name: A\n    type: MONEY\n"), types.YAML)
+	if !errors.As(err, &se) || se.Column != "A" {
+		t.Errorf("expected a SchemaException for an unknown type but got %v", err)
+	}
+	_, err = types.ReadSchema(strings.NewReader(`{"columns":[{"name":"A","type":"CUSTOM","converter":"missing","stringify":"cents"}]}`), types.JSON)
+	if !errors.As(err, &se) {
+		t.Errorf("expected a SchemaException for an unregistered converter but got %v", err)
+	}
+	_, err = types.ReadSchema(strings.NewReader(`{"columns":[{"name":"A","type":"STRING","requried":true}]}`), types.JSON)
+	if err == nil {
+		t.Error("expected an error for an unknown json key")
+	}
+	var config struct {
+		Schema *types.Schema `json:"schema"`
+	}
+	if err = json.Unmarshal([]byte(`{"schema":{"columns":[{"name":"A","type":"STRING","requried":true}]}}`), &config); err == nil {
+		t.Error("expected an error for an unknown key of an embedded json schema")
+	}
+	if _, err = types.ReadSchema(strings.NewReader(`{"columns":[{"name":"A","type":"STRING"}]} {}`), types.JSON); err == nil {
+		t.Error("expected an error for data after the json schema")
+	}
+	unknown := types.Index("B")(stringSchema(t, "A").(*types.Schema))
+	if err = unknown.Err(); !errors.As(err, &se) || se.Column != "B" || unknown.Indexed("B") {
+		t.Errorf("expected a SchemaException and no index for an unknown column but got %v", err)
+	}
+	field, err := types.NewField(pkg.CUSTOM, &pkg.Nullable{Allowed: true},
+		types.Override(types.Convert, pkg.FieldLevelConverter(func(in *string, _ ...interface{}) (interface{}, error) { return *in, nil })),
+		types.Override(types.ToString, pkg.StringifyField(types.SimpleToString)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = types.WriteSchema(new(bytes.Buffer), types.NewSchema(types.Column("A", field, true)), types.JSON); !errors.As(err, &se) {
+		t.Errorf("expected a SchemaException writing an unregistered converter but got %v", err)
+	}
+
+	keyed := "columns:\n  - {name: Loan ID, type: STRING}\n  - {name: Filing Type, type: STRING}\nkeys:\n  - {name: filing, columns: [Loan ID, Filing Type], policy: KEEP_LAST}\n"
+	if def, err = types.ReadSchema(strings.NewReader(keyed), types.YAML); err != nil {
+		t.Fatal(err)
+	}
+	if k := def.(*types.Schema).Keys(); len(k) != 1 || k[0].Policy != types.KeepLast || len(k[0].Columns) != 2 {
+		t.Errorf("unexpected keys %+v", k)
+	}
+	yml.Reset()
+	if err = types.WriteSchema(&yml, def, types.YAML); err != nil || !strings.Contains(yml.String(), "policy: KEEP_LAST") {
+		t.Errorf("expected the key to be written but got %v\n%s", err, yml.String())
+	}
+
+	if def, err = types.ReadSchema(strings.NewReader(`{"columns":[{"name":"Fee","type":"DECIMAL","decimal":[12,4]}]}`), types.JSON); err != nil {
+		t.Fatal(err)
+	}
+	if precision, scale := def.(*types.Schema).Cols()[0].Field.Precision(); precision != 12 || scale != 4 {
+		t.Errorf("expected decimal(12,4) but got decimal(%d,%d)", precision, scale)
+	}
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	type dates struct {
+		Mailed *time.Time `exttra:"Date UCC mailed,type=DATE"`
+	}
+	type loan struct {
+		Id     string  `exttra:"Loan ID,required,unique,alias=LoanNumber"`
+		Amount float64 `exttra:",required,null=NULL|n/a"`
+		Term   int
+		Dates  dates
+		Notes  []string `exttra:"-"`
+	}
+	def := types.SchemaFromStruct(loan{})
+	s := def.(*types.Schema)
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expect := []struct {
+		name     string
+		t        pkg.FieldType
+		required bool
+		nullable bool
+	}{
+		{"Loan ID", pkg.STRING, true, false},
+		{"Amount", pkg.FLOAT64, true, true},
+		{"Term", pkg.INT64, false, false},
+		{"Date UCC mailed", pkg.DATE, false, true},
+	}
+	if len(s.Cols()) != len(expect) {
+		t.Fatalf("expected %d columns but got %d", len(expect), len(s.Cols()))
+	}
+	for ii, e := range expect {
+		c := s.Cols()[ii]
+		if c.Name != e.name || c.Field.T != e.t || c.Required != e.required || c.Field.Nil.Allowed != e.nullable {
+			t.Errorf("expected %+v but got %s %s required=%v nullable=%v", e, c.Name, c.Field.T, c.Required, c.Field.Nil.Allowed)
+		}
+	}
+	if c := s.Cols()[0]; !c.Unique || len(c.Aliases) != 1 || c.Aliases[0] != "LoanNumber" {
+		t.Errorf("unexpected Loan ID definition %+v", c)
+	}
+
+	// the struct is the shape of the output without aliases
+	src := "LoanNumber,Amount,Term,Date UCC mailed\n1001,250.25,36,2019-01-01\n1002,n/a,12,\n"
+	in := input.Csv(strings.NewReader(src), def)
+	p := parser.NewParser(&in)
+	if err := p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]interface{}, 0)
+	if err = output.Mem(root, loan{}, &out).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]loan{}
+	for _, v := range out {
+		got[v.(loan).Id] = v.(loan)
+	}
+	day := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	if l := got["1001"]; l.Amount != 250.25 || l.Term != 36 || l.Dates.Mailed == nil || !l.Dates.Mailed.Equal(day) {
+		t.Errorf("unexpected row %+v", l)
+	}
+	if l := got["1002"]; l.Amount != 0 || l.Term != 12 || l.Dates.Mailed != nil {
+		t.Errorf("unexpected row %+v", l)
+	}
+
+	// and the struct is read back from memory
+	loans := []loan{got["1001"], got["1002"]}
+	if ids := parseColumn(t, input.Mem(loans, def), "Loan ID"); strings.Join(ids, ",") != "1001,1002" {
+		t.Errorf("expected 1001,1002 but got %v", ids)
+	}
+
+	type invalid struct {
+		Id string `exttra:"Loan ID,primary"`
+	}
+	var se *pkg.SchemaException
+	if err = types.SchemaFromStruct(invalid{}).(*types.Schema).Err(); !errors.As(err, &se) {
+		t.Errorf("expected a SchemaException for an unknown tag option but got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loanpal-engineering/exttra/io/input"
+	"github.com/loanpal-engineering/exttra/parser"
+	"github.com/loanpal-engineering/exttra/pkg"
+	"github.com/loanpal-engineering/exttra/types"
+)
+
+// A fake driver serving a single table, every query returns the table
+type (
+	fakeDriver struct{}
+	fakeConn   struct{}
+	fakeStmt   struct {
+		query string
+	}
+	fakeRows struct {
+		rows [][]driver.Value
+	}
+)
+
+var (
+	fakeColumns = []string{"loan_id", "amount", "mailed", "comment"}
+	fakeKinds   = []string{"VARCHAR", "INTEGER", "DATE", "TEXT"}
+	fakeTable   = [][]driver.Value{
+		{"1001", int64(250), time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), []byte("first")},
+		{"1002", int64(10), nil, nil},
+	}
+	// served for queries on the nulls table, the middle row is all NULL
+	fakeNulls = [][]driver.Value{
+		{"1001", int64(250), time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), []byte("first")},
+		{nil, nil, nil, nil},
+		{"1003", int64(5), nil, []byte("third")},
+	}
+)
+
+func init() {
+	sql.Register("exttra-fake", fakeDriver{})
+}
+
+func (fakeDriver) Open(string) (driver.Conn, error)    { return fakeConn{}, nil }
+func (fakeConn) Prepare(q string) (driver.Stmt, error) { return fakeStmt{q}, nil }
+func (fakeConn) Close() error                          { return nil }
+func (fakeConn) Begin() (driver.Tx, error)             { return nil, errors.New("fake: no transactions") }
+func (fakeStmt) Close() error                          { return nil }
+func (fakeStmt) NumInput() int                         { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("fake: read only")
+}
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	if strings.Contains(s.query, "nulls") {
+		return &fakeRows{rows: fakeNulls}, nil
+	}
+	return &fakeRows{rows: fakeTable}, nil
+}
+func (*fakeRows) Columns() []string                       { return fakeColumns }
+func (*fakeRows) Close() error                            { return nil }
+func (*fakeRows) ColumnTypeDatabaseTypeName(i int) string { return fakeKinds[i] }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQL(t *testing.T) {
+	db, err := sql.Open("exttra-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	explicitNull := "NULL"
+	nullable := &pkg.Nullable{Allowed: true, ReplaceWith: &explicitNull}
+	id, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: false})
+	amount, _ := types.NewField(pkg.INT32, nullable)
+	mailed, _ := types.NewField(pkg.DATE, nullable)
+	comment, _ := types.NewField(pkg.STRING, nullable)
+	def := types.NewSchema(
+		types.Column("Loan ID", id, true, true),
+		types.Column("Amount", amount, true),
+		types.Column("Mailed", mailed, true),
+		types.Column("Comment", comment, false),
+		types.Alias("Loan ID", "loan_id"),
+		types.Alias("Amount", "amount"),
+		types.Alias("Mailed", "mailed"),
+		types.Alias("Comment", "comment"))
+
+	in := input.Query(db, "SELECT * FROM loans", def)
+	p := parser.NewParser(&in)
+	if err = p.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	root, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string][]interface{}{
+		"Loan ID": {"1001", "1002"},
+		"Amount":  {int32(250), int32(10)},
+		"Mailed":  {time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), nil},
+		"Comment": {"first", nil},
+	}
+	for name, values := range expect {
+		col := root.Find(name)
+		if col == nil {
+			t.Fatalf("column %s not found", name)
+		}
+		_, ci, _ := col.Id()
+		for ri, v := range values {
+			n := col.FindById(pkg.GenNodeId(ci, uint32(ri+1)))
+			if n == nil {
+				t.Fatalf("%s: row %d not found", name, ri+1)
+			}
+			if got := n.Value(); got != v {
+				t.Errorf("%s: expected %v but got %v", name, v, got)
+			}
+		}
+	}
+
+	before := pkg.NewDC().Count()
+	rows, err := db.Query("SELECT loan_id, comment FROM loans")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonNil, _ := types.NewField(pkg.STRING, &pkg.Nullable{Allowed: false})
+	strict := types.NewSchema(types.Column("comment", nonNil, true))
+	if got := parseColumn(t, input.SQL(rows, strict), "comment"); strings.Join(got, ",") != "first," {
+		t.Errorf("unexpected comments %v", got)
+	}
+	if d := pkg.NewDC().Count() - before; d != 1 {
+		t.Errorf("expected 1 defect for a NULL in a non nullable column but got %d", d)
+	}
+
+	// an all NULL row is a row of nulls, not the end of the body
+	rows, err = db.Query("SELECT comment FROM nulls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	optional := types.NewSchema(types.Column("comment", comment, true))
+	if got := parseColumn(t, input.SQL(rows, optional), "comment"); strings.Join(got, ",") != "first,,third" {
+		t.Errorf("unexpected comments %v", got)
+	}
+}
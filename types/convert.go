@@ -18,6 +18,15 @@ var (
 	specialChars = regexp.MustCompile("[ $%,()a-zA-z]")
 )
 
+// Strip the symbols of a formatted number, a number in parentheses is a negative, ex. ($1,200) is -1200
+func plain(in string) string {
+	value := strings.TrimSpace(in)
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		return "-" + specialChars.ReplaceAllString(value, "")
+	}
+	return specialChars.ReplaceAllString(value, "")
+}
+
 // Default ToString
 func SimpleToString(it interface{}) *string {
 	empty := ""
@@ -36,6 +45,10 @@ func SimpleToString(it interface{}) *string {
 		}
 	case time.Time:
 		val = it.(time.Time).Format(time.RFC3339)
+	case pkg.Decimal:
+		val = it.(pkg.Decimal).String()
+	case pkg.Category:
+		val = it.(pkg.Category).String()
 	case float64:
 		val = fmt.Sprint(it.(float64))
 	case float32:
@@ -104,7 +117,7 @@ func BoolConverter(in *string, _ ...interface{}) (interface{}, error) {
 
 func int8Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 	var base int64
-	if base, err = strconv.ParseInt(specialChars.ReplaceAllString(*in, ""), 10, 8); err != nil {
+	if base, err = strconv.ParseInt(plain(*in), 10, 8); err != nil {
 		return
 	}
 	out = int8(base)
@@ -112,7 +125,7 @@ func int8Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 }
 func int16Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 	var base int64
-	if base, err = strconv.ParseInt(specialChars.ReplaceAllString(*in, ""), 10, 16); err != nil {
+	if base, err = strconv.ParseInt(plain(*in), 10, 16); err != nil {
 		return
 	}
 	out = int16(base)
@@ -120,7 +133,7 @@ func int16Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 }
 func int32Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 	var base int64
-	if base, err = strconv.ParseInt(specialChars.ReplaceAllString(*in, ""), 10, 32); err != nil {
+	if base, err = strconv.ParseInt(plain(*in), 10, 32); err != nil {
 		return
 	}
 	out = int32(base)
@@ -128,7 +141,7 @@ func int32Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 }
 func int64Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 	var base int64
-	if base, err = strconv.ParseInt(specialChars.ReplaceAllString(*in, ""), 10, 64); err != nil {
+	if base, err = strconv.ParseInt(plain(*in), 10, 64); err != nil {
 		return
 	}
 	out = base
@@ -136,7 +149,7 @@ func int64Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 }
 func uint8Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 	var base uint64
-	if base, err = strconv.ParseUint(specialChars.ReplaceAllString(*in, ""), 10, 8); err != nil {
+	if base, err = strconv.ParseUint(plain(*in), 10, 8); err != nil {
 		return
 	}
 	out = uint8(base)
@@ -144,7 +157,7 @@ func uint8Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 }
 func uint16Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 	var base uint64
-	if base, err = strconv.ParseUint(specialChars.ReplaceAllString(*in, ""), 10, 16); err != nil {
+	if base, err = strconv.ParseUint(plain(*in), 10, 16); err != nil {
 		return
 	}
 	out = uint16(base)
@@ -152,7 +165,7 @@ func uint16Converter(in *string, _ ...interface{}) (out interface{}, err error)
 }
 func uint32Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 	var base uint64
-	if base, err = strconv.ParseUint(specialChars.ReplaceAllString(*in, ""), 10, 32); err != nil {
+	if base, err = strconv.ParseUint(plain(*in), 10, 32); err != nil {
 		return
 	}
 	out = uint32(base)
@@ -160,7 +173,7 @@ func uint32Converter(in *string, _ ...interface{}) (out interface{}, err error)
 }
 func uint64Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 	var base uint64
-	if base, err = strconv.ParseUint(specialChars.ReplaceAllString(*in, ""), 10, 64); err != nil {
+	if base, err = strconv.ParseUint(plain(*in), 10, 64); err != nil {
 		return
 	}
 	out = base
@@ -191,14 +204,14 @@ func IntConverter(t pkg.FieldType) pkg.FieldLevelConverter {
 	case pkg.UINT64:
 		return uint64Converter
 	default:
-		panic(fmt.Sprintf("types/convert: Int %s not supported", t.String()))
+		return unsupported(fmt.Sprintf("types/convert: Int %s not supported", t.String()))
 	}
 }
 
 // Convert a field's value to an float64.
 func float32Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 	var base float64
-	rmSpecialChars := specialChars.ReplaceAllString(*in, "")
+	rmSpecialChars := plain(*in)
 	if base, err = strconv.ParseFloat(rmSpecialChars, 32); err != nil {
 		return
 	} else {
@@ -210,7 +223,7 @@ func float32Converter(in *string, _ ...interface{}) (out interface{}, err error)
 // Convert a field's value to an float64.
 func float64Converter(in *string, _ ...interface{}) (out interface{}, err error) {
 	var base float64
-	rmSpecialChars := specialChars.ReplaceAllString(*in, "")
+	rmSpecialChars := plain(*in)
 	if base, err = strconv.ParseFloat(rmSpecialChars, 64); err != nil {
 		return
 	} else {
@@ -229,11 +242,77 @@ func FloatConverter(t pkg.FieldType) pkg.FieldLevelConverter {
 	case pkg.FLOAT64:
 		return float64Converter
 	default:
-		panic(fmt.Sprintf("types/convert: float %s not supported", t.String()))
+		return unsupported(fmt.Sprintf("types/convert: float %s not supported", t.String()))
 	}
 
 }
 
+// Convert a field's value to a decimal of [precision] digits, [scale] of them after the point.
+// Currency signs, thousands separators and a negative in parentheses are accepted, ex. ($1,200.50) is -1200.50.
+// A comma is a thousands separator, a comma not between groups of 3 digits, ex. 1,5, is an error.
+// Digits beyond the scale are rounded half away from zero, a value of more digits than [precision] is an error.
+func DecimalConverter(precision, scale uint8) pkg.FieldLevelConverter {
+	if precision == 0 || precision > pkg.MaxPrecision || scale > precision {
+		return unsupported(fmt.Sprintf("types/convert: decimal(%d,%d) not supported", precision, scale))
+	}
+	return func(in *string, _ ...interface{}) (interface{}, error) {
+		value := strings.TrimSpace(*in)
+		negative := strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")")
+		if negative {
+			value = value[1 : len(value)-1]
+		}
+		value, grouped := ungroup(strings.NewReplacer("$", "", " ", "").Replace(value), '.', func(r rune) bool { return r == ',' })
+		d, err := pkg.ParseDecimal(value, scale)
+		if !grouped || err != nil || negative && d.Unscaled < 0 {
+			return nil, errors.New(fmt.Sprintf("types/convert: can not convert \"%s\" to a decimal", *in))
+		}
+		if negative {
+			d.Unscaled = -d.Unscaled
+		}
+		if d.Precision() > int(precision) {
+			return nil, errors.New(fmt.Sprintf("types/convert: \"%s\" exceeds decimal(%d,%d)", *in, precision, scale))
+		}
+		return d, nil
+	}
+}
+
+// Remove the grouping separators of the integer part of [value], the part before [point].
+// A separator is only accepted between complete groups of 3 digits, ex. 1,234,567 but not 1,5 or 1,2,3.
+func ungroup(value string, point rune, separator func(rune) bool) (string, bool) {
+	var (
+		b       strings.Builder
+		group   = 0
+		grouped = false
+		integer = true
+	)
+	for _, r := range value {
+		switch {
+		case integer && separator(r):
+			if group == 0 || group > 3 || grouped && group != 3 {
+				return "", false
+			}
+			group, grouped = 0, true
+			continue
+		case r == point:
+			integer = false
+		case integer && r >= '0' && r <= '9':
+			group++
+		}
+		b.WriteRune(r)
+	}
+	if grouped && group != 3 {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// A converter failing every conversion, returned for a field type a converter does not support
+func unsupported(msg string) pkg.FieldLevelConverter {
+	return func(*string, ...interface{}) (interface{}, error) {
+		return nil, errors.New(msg)
+	}
+}
+
 // Convert a field's value to an time instance.
 func DateTimeConverter(in *string, retry ...interface{}) (interface{}, error) {
 	if t, err := dateparse.ParseAny(strings.TrimSpace(*in)); err != nil {
@@ -0,0 +1,192 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+	"github.com/loanpal-engineering/exttra/pkg"
+)
+
+type (
+	// The date/time policy of a DATE or TIMESTAMP field, see [Layouts], [SourceZone], [TargetZone] and [Strict]
+	timePolicy struct {
+		layouts []string
+		source  *time.Location
+		target  *time.Location
+		strict  bool
+	}
+)
+
+// Layout of a DATE value written to a flat file
+const DateLayout = "2006-01-02"
+
+// Read the values of a DATE or TIMESTAMP field with the Go time [layouts], tried in order.
+// A value matching no layout is read by [DateTimeConverter], unless the field is [Strict].
+//
+//	field, err := types.NewField(pkg.DATE, nullable, types.Layouts("02/01/2006", "02.01.2006"), types.Strict())
+func Layouts(layouts ...string) FieldOverride {
+	return timeOverride(func(tp *timePolicy) error {
+		if len(layouts) == 0 {
+			return errors.New("layouts requires one or more layouts")
+		}
+		tp.layouts = append([]string(nil), layouts...)
+		return nil
+	})
+}
+
+// Read the values of a DATE or TIMESTAMP field without a zone or offset in [loc], values are read in UTC by default.
+// A value with a zone or offset keeps it.
+func SourceZone(loc *time.Location) FieldOverride {
+	return timeOverride(func(tp *timePolicy) error {
+		if loc == nil {
+			return errors.New("source zone requires a location")
+		}
+		tp.source = loc
+		return nil
+	})
+}
+
+// Convert the values of a DATE or TIMESTAMP field to [loc], a DATE is the calendar date in [loc].
+func TargetZone(loc *time.Location) FieldOverride {
+	return timeOverride(func(tp *timePolicy) error {
+		if loc == nil {
+			return errors.New("target zone requires a location")
+		}
+		tp.target = loc
+		return nil
+	})
+}
+
+// Never guess a DATE or TIMESTAMP value, a value matching none of the field's layouts is a defect.
+// Without layouts a date whose day and month order is not given by its format, ex. 04/02/2014, is a defect.
+func Strict() FieldOverride {
+	return timeOverride(func(tp *timePolicy) error {
+		tp.strict = true
+		return nil
+	})
+}
+
+// Get the layouts, zones and strict mode of a DATE or TIMESTAMP field, see [Layouts]
+func (f *Field) TimePolicy() (layouts []string, source, target *time.Location, strict bool) {
+	if f.datetime == nil {
+		return nil, nil, nil, false
+	}
+	return f.datetime.layouts, f.datetime.source, f.datetime.target, f.datetime.strict
+}
+
+func timeOverride(fn func(*timePolicy) error) FieldOverride {
+	return func(f *Field) (*Field, error) {
+		if f.T != pkg.DATE && f.T != pkg.TIMESTAMP {
+			return f, errors.New("a date/time policy is only set on a date or timestamp field")
+		}
+		tp := timePolicy{}
+		if f.datetime != nil {
+			tp = *f.datetime
+		}
+		if err := fn(&tp); err != nil {
+			return f, err
+		}
+		f.datetime = &tp
+		f.convert = TimeConverter(f.T, tp.layouts, tp.source, tp.target, tp.strict)
+		return f, nil
+	}
+}
+
+// Convert a field's value to a time following a date/time policy, see [Layouts], [SourceZone], [TargetZone] and [Strict].
+// A DATE is truncated to midnight of its calendar date, in the target zone when set.
+func TimeConverter(t pkg.FieldType, layouts []string, source, target *time.Location, strict bool) pkg.FieldLevelConverter {
+	if source == nil {
+		source = time.UTC
+	}
+	return func(in *string, _ ...interface{}) (interface{}, error) {
+		var (
+			value   = strings.TrimSpace(*in)
+			parsed  time.Time
+			matched bool
+			err     error
+		)
+		for _, layout := range layouts {
+			if parsed, err = time.ParseInLocation(layout, value, source); err == nil {
+				matched = true
+				break
+			}
+		}
+		switch {
+		case matched:
+		case len(layouts) > 0 && strict:
+			return nil, errors.New(fmt.Sprintf("types/convert: \"%s\" matches none of the layouts %s", *in, strings.Join(layouts, ", ")))
+		case strict:
+			if parsed, err = dateparse.ParseStrict(value); err != nil {
+				return nil, errors.New(fmt.Sprintf("types/convert: \"%s\" %s", *in, strictReason(err)))
+			}
+			parsed = inZone(value, parsed, source)
+		default:
+			var v interface{}
+			if v, err = DateTimeConverter(in); err != nil {
+				return nil, err
+			}
+			parsed = inZone(value, v.(time.Time), source)
+		}
+		return settle(t, parsed, target), nil
+	}
+}
+
+// Apply the date/time policy of a DATE or TIMESTAMP field to a typed time, ex. a value of input.Mem or input.SQL.
+// A time in UTC carries no zone of its own and is moved to the same wall clock in the source zone,
+// it is then converted to the target zone and a DATE is truncated to its calendar date.
+func (f *Field) ConvertTime(t time.Time) time.Time {
+	var source, target *time.Location
+	if f.datetime != nil {
+		source, target = f.datetime.source, f.datetime.target
+	}
+	if source != nil && t.Location() == time.UTC {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), source)
+	}
+	return settle(f.T, t, target)
+}
+
+// Convert a time to [target] when set, a DATE is truncated to midnight of its calendar date
+func settle(ft pkg.FieldType, t time.Time, target *time.Location) time.Time {
+	if target != nil {
+		t = t.In(target)
+	}
+	if ft == pkg.DATE {
+		y, m, d := t.Date()
+		t = time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+	return t
+}
+
+func strictReason(err error) string {
+	if err == dateparse.ErrAmbiguousMMDD {
+		return "is an ambiguous date, its day and month order requires a layout"
+	}
+	return "is not a known date/time"
+}
+
+// Move a guessed time without a zone or offset from UTC to the same wall clock in [loc]
+func inZone(value string, t time.Time, loc *time.Location) time.Time {
+	if loc == time.UTC {
+		return t
+	}
+	if layout, err := dateparse.ParseFormat(value); err == nil && zoned(layout) {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// Does a Go time layout read a zone or offset, dateparse keeps a literal Z or UTC in the layout
+func zoned(layout string) bool {
+	if strings.HasSuffix(layout, "Z") {
+		return true
+	}
+	for _, z := range []string{"MST", "Z07", "-07", "UTC", "GMT"} {
+		if strings.Contains(layout, z) {
+			return true
+		}
+	}
+	return false
+}
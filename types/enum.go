@@ -0,0 +1,137 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+)
+
+type (
+	// The values of an ENUM field, see [Enum]
+	enumDef struct {
+		categories *pkg.Categories
+		// synonyms of each value, in the order they are set
+		synonyms      map[string][]string
+		caseSensitive bool
+	}
+)
+
+// Set the allowed [values] of an ENUM field, a value is stored as its index in [values].
+// Values are matched ignoring case and surrounding or repeated whitespace, see [CaseSensitive].
+// Any other value is a defect. The field converts with its values, a Convert override is an error.
+//
+//	status, err := types.NewField(pkg.ENUM, nullable, types.Enum("CA", "NV"), types.Synonyms("CA", "Calif.", "California"))
+func Enum(values ...string) FieldOverride {
+	return enumOverride(func(e *enumDef) error {
+		if len(values) == 0 || len(values) > math.MaxUint16+1 {
+			return errors.New(fmt.Sprintf("an enum requires 1 to %d values", math.MaxUint16+1))
+		}
+		e.categories = &pkg.Categories{Values: append([]string(nil), values...)}
+		return nil
+	})
+}
+
+// Read each of [synonyms] as [value] in an ENUM field, ex. Calif. as CA.
+// [value] is matched to the enum values as a converted value is.
+func Synonyms(value string, synonyms ...string) FieldOverride {
+	return enumOverride(func(e *enumDef) error {
+		if e.synonyms == nil {
+			e.synonyms = make(map[string][]string)
+		}
+		e.synonyms[value] = append(e.synonyms[value], synonyms...)
+		return nil
+	})
+}
+
+// Match the values and synonyms of an ENUM field by case, whitespace is still normalised
+func CaseSensitive() FieldOverride {
+	return enumOverride(func(e *enumDef) error {
+		e.caseSensitive = true
+		return nil
+	})
+}
+
+// Get the categories and synonyms of an ENUM field, see [Enum]
+func (f *Field) Enum() (*pkg.Categories, map[string][]string) {
+	if f.enum == nil {
+		return nil, nil
+	}
+	return f.enum.categories, f.enum.synonyms
+}
+
+func enumOverride(fn func(*enumDef) error) FieldOverride {
+	return func(f *Field) (*Field, error) {
+		if f.T != pkg.ENUM {
+			return f, errors.New("enum values are only set on an enum field")
+		}
+		e := enumDef{}
+		if f.enum != nil {
+			e = *f.enum
+		}
+		if err := fn(&e); err != nil {
+			return f, err
+		}
+		// the converter is built by NewField once every option is set
+		f.enum = &e
+		return f, nil
+	}
+}
+
+// Build the converter of the enum, each value and synonym is looked up by its normalised form
+func (e *enumDef) converter() (pkg.FieldLevelConverter, error) {
+	lookup := make(map[string]pkg.Category, len(e.categories.Values))
+	add := func(key string, c pkg.Category) error {
+		key = e.normalise(key)
+		if other, exists := lookup[key]; exists && other.Code != c.Code {
+			return errors.New(fmt.Sprintf("%s is both %s and %s", key, other, c))
+		}
+		lookup[key] = c
+		return nil
+	}
+	for ii := range e.categories.Values {
+		if err := add(e.categories.Values[ii], pkg.Category{Code: uint16(ii), Of: e.categories}); err != nil {
+			return nil, err
+		}
+	}
+	for value, synonyms := range e.synonyms {
+		c, ok := e.category(value)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("synonyms of %s, which is not an enum value", value))
+		}
+		for _, s := range synonyms {
+			if err := add(s, c); err != nil {
+				return nil, err
+			}
+		}
+	}
+	values := strings.Join(e.categories.Values, ", ")
+	return func(in *string, _ ...interface{}) (interface{}, error) {
+		if c, ok := lookup[e.normalise(*in)]; ok {
+			return c, nil
+		}
+		return nil, errors.New(fmt.Sprintf("types/convert: \"%s\" is not one of %s", *in, values))
+	}, nil
+}
+
+// Find the enum value of [value], matched as a converted value is
+func (e *enumDef) category(value string) (pkg.Category, bool) {
+	key := e.normalise(value)
+	for ii, v := range e.categories.Values {
+		if e.normalise(v) == key {
+			return pkg.Category{Code: uint16(ii), Of: e.categories}, true
+		}
+	}
+	return pkg.Category{}, false
+}
+
+// Trim and collapse the whitespace of [value], and fold its case unless the enum is case sensitive
+func (e *enumDef) normalise(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if e.caseSensitive {
+		return value
+	}
+	return strings.ToLower(value)
+}
@@ -0,0 +1,500 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// Format of a schema file
+	Format int
+
+	// The document of a schema file
+	schemaFile struct {
+		Columns    []columnFile `json:"columns" yaml:"columns"`
+		Indices    []string     `json:"indices,omitempty" yaml:"indices,omitempty"`
+		Keys       []keyFile    `json:"keys,omitempty" yaml:"keys,omitempty"`
+		Positional bool         `json:"positional,omitempty" yaml:"positional,omitempty"`
+	}
+	keyFile struct {
+		Name    string   `json:"name" yaml:"name"`
+		Columns []string `json:"columns" yaml:"columns"`
+		Policy  string   `json:"policy,omitempty" yaml:"policy,omitempty"`
+	}
+	columnFile struct {
+		Name     string       `json:"name" yaml:"name"`
+		Type     string       `json:"type" yaml:"type"`
+		Required bool         `json:"required,omitempty" yaml:"required,omitempty"`
+		Unique   bool         `json:"unique,omitempty" yaml:"unique,omitempty"`
+		Aliases  []string     `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+		Nullable nullableFile `json:"nullable" yaml:"nullable"`
+		Position *int         `json:"position,omitempty" yaml:"position,omitempty"`
+		Span     []int        `json:"span,omitempty" yaml:"span,omitempty"`
+		// precision and scale of a DECIMAL column
+		Decimal []int `json:"decimal,omitempty" yaml:"decimal,omitempty"`
+		// date/time policy of a DATE or TIMESTAMP column, zones are IANA names
+		Layouts    []string `json:"layouts,omitempty" yaml:"layouts,omitempty"`
+		SourceZone string   `json:"sourceZone,omitempty" yaml:"sourceZone,omitempty"`
+		TargetZone string   `json:"targetZone,omitempty" yaml:"targetZone,omitempty"`
+		Strict     bool     `json:"strict,omitempty" yaml:"strict,omitempty"`
+		// numeric conventions of a numeric column, see [Locale]
+		Number *numberFile `json:"number,omitempty" yaml:"number,omitempty"`
+		// values of an ENUM column, see [Enum]
+		Enum *enumFile `json:"enum,omitempty" yaml:"enum,omitempty"`
+		// business rules of the column, see [NotBlank]
+		Rules *rulesFile `json:"rules,omitempty" yaml:"rules,omitempty"`
+		// registry names, see [Registered]
+		Converter string `json:"converter,omitempty" yaml:"converter,omitempty"`
+		Stringify string `json:"stringify,omitempty" yaml:"stringify,omitempty"`
+		Extension string `json:"extension,omitempty" yaml:"extension,omitempty"`
+	}
+	nullableFile struct {
+		Allowed     bool     `json:"allowed" yaml:"allowed"`
+		Variants    []string `json:"variants,omitempty" yaml:"variants,omitempty"`
+		ReplaceWith *string  `json:"replaceWith,omitempty" yaml:"replaceWith,omitempty"`
+	}
+	enumFile struct {
+		Values        []string            `json:"values" yaml:"values"`
+		Synonyms      map[string][]string `json:"synonyms,omitempty" yaml:"synonyms,omitempty"`
+		CaseSensitive bool                `json:"caseSensitive,omitempty" yaml:"caseSensitive,omitempty"`
+	}
+	rulesFile struct {
+		NotBlank  bool     `json:"notBlank,omitempty" yaml:"notBlank,omitempty"`
+		MinLength *int     `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+		MaxLength *int     `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+		Pattern   string   `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+		Min       *float64 `json:"min,omitempty" yaml:"min,omitempty"`
+		Max       *float64 `json:"max,omitempty" yaml:"max,omitempty"`
+		// RFC 3339 times or 2006-01-02 dates
+		Earliest  string `json:"earliest,omitempty" yaml:"earliest,omitempty"`
+		Latest    string `json:"latest,omitempty" yaml:"latest,omitempty"`
+		NotFuture bool   `json:"notFuture,omitempty" yaml:"notFuture,omitempty"`
+	}
+	numberFile struct {
+		Decimal    string   `json:"decimal" yaml:"decimal"`
+		Grouping   string   `json:"grouping,omitempty" yaml:"grouping,omitempty"`
+		Percent    bool     `json:"percent,omitempty" yaml:"percent,omitempty"`
+		Currencies []string `json:"currencies,omitempty" yaml:"currencies,omitempty"`
+	}
+)
+
+const (
+	JSON Format = iota
+	YAML
+)
+
+// Read a schema file.
+// Custom converters, stringify functions and extensions are referenced by their registry name, see [RegisterConverter].
+// Unknown keys are an error in both formats.
+//
+//	columns:
+//	  - name: Loan ID
+//	    type: STRING
+//	    required: true
+//	    unique: true
+//	    aliases: [LoanNumber]
+//	    nullable: {allowed: false}
+//	  - name: Amount
+//	    type: CUSTOM
+//	    converter: money
+//	    stringify: money
+//	    nullable: {allowed: true, variants: [NULL], replaceWith: "0"}
+//	  - name: Fee
+//	    type: DECIMAL
+//	    decimal: [12, 2]
+//	    number: {decimal: ",", grouping: ".", currencies: [EUR]}
+//	  - name: State
+//	    type: ENUM
+//	    enum: {values: [CA, NV], synonyms: {CA: [Calif., California]}}
+//	  - name: Funded
+//	    type: DATE
+//	    layouts: [02/01/2006]
+//	    sourceZone: Europe/London
+//	    strict: true
+//	    rules: {earliest: 2000-01-01, notFuture: true}
+//	indices: [Loan ID]
+//	keys:
+//	  - {name: filing, columns: [Loan ID, Filing Type], policy: KEEP_LAST}
+func ReadSchema(r io.Reader, f Format) (Signature, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	s := new(Schema)
+	switch f {
+	case JSON:
+		err = s.UnmarshalJSON(b)
+	case YAML:
+		err = yaml.UnmarshalStrict(b, s)
+	default:
+		err = errors.New("types/file: unknown schema file format")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write a schema to a schema file, see [ReadSchema].
+// A field using functions that are not registered can not be written.
+func WriteSchema(w io.Writer, def Signature, f Format) error {
+	s, ok := def.(*Schema)
+	if !ok || s == nil {
+		return errors.New("types/file: schema bad cast")
+	}
+	var (
+		b   []byte
+		err error
+	)
+	switch f {
+	case JSON:
+		var doc schemaFile
+		if doc, err = s.document(); err == nil {
+			b, err = json.MarshalIndent(doc, "", "  ")
+			b = append(b, '\n')
+		}
+	case YAML:
+		b, err = yaml.Marshal(s)
+	default:
+		err = errors.New("types/file: unknown schema file format")
+	}
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, bytes.NewReader(b))
+	return err
+}
+
+// Read the schema file at [path], the format is chosen by the extension: .json, .yaml or .yml
+func LoadSchema(path string) (Signature, error) {
+	var f Format
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		f = JSON
+	case ".yaml", ".yml":
+		f = YAML
+	default:
+		return nil, errors.New(fmt.Sprintf("types/file: unknown schema file extension %s", filepath.Ext(path)))
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadSchema(file, f)
+}
+
+// Encode the schema as a schema file document
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	doc, err := s.document()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// Decode a schema file document, the schema is replaced.
+// Unknown keys are rejected as with yaml, a misspelled property is not silently ignored.
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	var doc schemaFile
+	if err := decoder.Decode(&doc); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("types/file: unexpected data after the schema document")
+	}
+	return s.load(doc)
+}
+
+// Encode the schema as a schema file document
+func (s *Schema) MarshalYAML() (interface{}, error) {
+	return s.document()
+}
+
+// Decode a schema file document, the schema is replaced
+func (s *Schema) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var doc schemaFile
+	if err := unmarshal(&doc); err != nil {
+		return err
+	}
+	return s.load(doc)
+}
+
+// Build the document of the schema.
+// The empty null variant added by [NewField] is implied and is not written.
+func (s *Schema) document() (schemaFile, error) {
+	doc := schemaFile{
+		Columns:    make([]columnFile, 0, len(s.columns)),
+		Indices:    s.indices,
+		Positional: s.positional,
+	}
+	for _, c := range s.columns {
+		cf := columnFile{
+			Name:      c.Name,
+			Type:      c.Field.T.String(),
+			Required:  c.Required,
+			Unique:    c.Unique,
+			Aliases:   c.Aliases,
+			Converter: c.Field.registered[Convert],
+			Stringify: c.Field.registered[ToString],
+			Extension: c.Field.registered[Extension],
+		}
+		if c.Field.T == pkg.CUSTOM && (cf.Converter == "" || cf.Stringify == "") {
+			return doc, pkg.NewSchemaException(c.Name, "custom converter and stringify functions must be registered to be written")
+		}
+		if c.Field.Extension != nil && cf.Extension == "" {
+			return doc, pkg.NewSchemaException(c.Name, "extension must be registered to be written")
+		}
+		if c.Field.Nil != nil {
+			cf.Nullable.Allowed = c.Field.Nil.Allowed
+			cf.Nullable.ReplaceWith = c.Field.Nil.ReplaceWith
+			for _, v := range c.Field.Nil.Variants {
+				if v != "" {
+					cf.Nullable.Variants = append(cf.Nullable.Variants, v)
+				}
+			}
+		}
+		if c.Position >= 0 {
+			p := c.Position
+			cf.Position = &p
+		}
+		if c.Width > 0 {
+			cf.Span = []int{c.Offset, c.Width}
+		}
+		if c.Field.T == pkg.DECIMAL {
+			precision, scale := c.Field.Precision()
+			cf.Decimal = []int{int(precision), int(scale)}
+		}
+		if nf := c.Field.Locale(); nf != nil {
+			cf.Number = &numberFile{
+				Decimal:    string(nf.Decimal),
+				Percent:    nf.Percent,
+				Currencies: nf.Currencies,
+			}
+			if nf.Grouping != 0 {
+				cf.Number.Grouping = string(nf.Grouping)
+			}
+		}
+		if categories, synonyms := c.Field.Enum(); categories != nil {
+			cf.Enum = &enumFile{Values: categories.Values, Synonyms: synonyms, CaseSensitive: c.Field.enum.caseSensitive}
+		}
+		if r := c.Field.rules; r != nil {
+			cf.Rules = &rulesFile{
+				NotBlank:  r.notBlank,
+				MinLength: r.minLength,
+				MaxLength: r.maxLength,
+				Min:       r.min,
+				Max:       r.max,
+				NotFuture: r.notFuture,
+			}
+			if r.pattern != nil {
+				cf.Rules.Pattern = r.pattern.String()
+			}
+			if r.earliest != nil {
+				cf.Rules.Earliest = r.earliest.Format(time.RFC3339)
+			}
+			if r.latest != nil {
+				cf.Rules.Latest = r.latest.Format(time.RFC3339)
+			}
+		}
+		layouts, source, target, strict := c.Field.TimePolicy()
+		cf.Layouts, cf.Strict = layouts, strict
+		if source != nil {
+			cf.SourceZone = source.String()
+		}
+		if target != nil {
+			cf.TargetZone = target.String()
+		}
+		doc.Columns = append(doc.Columns, cf)
+	}
+	for _, k := range s.keys {
+		doc.Keys = append(doc.Keys, keyFile{Name: k.Name, Columns: k.Columns, Policy: k.Policy.String()})
+	}
+	return doc, nil
+}
+
+// Replace the schema with the schema of a document
+func (s *Schema) load(doc schemaFile) error {
+	opts := make([]Opt, 0, len(doc.Columns)*2+len(doc.Indices)+1)
+	for _, cf := range doc.Columns {
+		t, ok := fieldType(cf.Type)
+		if !ok {
+			return pkg.NewSchemaException(cf.Name, fmt.Sprintf("unknown type %s", cf.Type))
+		}
+		overrides := make([]FieldOverride, 0, 8)
+		if len(cf.Layouts) > 0 {
+			overrides = append(overrides, Layouts(cf.Layouts...))
+		}
+		for _, z := range []struct {
+			name     string
+			override func(*time.Location) FieldOverride
+		}{{cf.SourceZone, SourceZone}, {cf.TargetZone, TargetZone}} {
+			if z.name == "" {
+				continue
+			}
+			loc, err := time.LoadLocation(z.name)
+			if err != nil {
+				return pkg.NewSchemaException(cf.Name, err.Error())
+			}
+			overrides = append(overrides, z.override(loc))
+		}
+		if cf.Strict {
+			overrides = append(overrides, Strict())
+		}
+		if cf.Number != nil {
+			nf := NumberFormat{Percent: cf.Number.Percent, Currencies: cf.Number.Currencies}
+			for _, sep := range []struct {
+				value string
+				r     *rune
+			}{{cf.Number.Decimal, &nf.Decimal}, {cf.Number.Grouping, &nf.Grouping}} {
+				if r := []rune(sep.value); len(r) == 1 {
+					*sep.r = r[0]
+				} else if len(r) > 1 {
+					return pkg.NewSchemaException(cf.Name, fmt.Sprintf("separator %s must be a single character", sep.value))
+				}
+			}
+			overrides = append(overrides, Locale(nf))
+		}
+		if cf.Enum != nil {
+			overrides = append(overrides, Enum(cf.Enum.Values...))
+			values := make([]string, 0, len(cf.Enum.Synonyms))
+			for v := range cf.Enum.Synonyms {
+				values = append(values, v)
+			}
+			sort.Strings(values)
+			for _, v := range values {
+				overrides = append(overrides, Synonyms(v, cf.Enum.Synonyms[v]...))
+			}
+			if cf.Enum.CaseSensitive {
+				overrides = append(overrides, CaseSensitive())
+			}
+		}
+		if cf.Rules != nil {
+			rules, err := cf.Rules.overrides()
+			if err != nil {
+				return pkg.NewSchemaException(cf.Name, err.Error())
+			}
+			overrides = append(overrides, rules...)
+		}
+		if len(cf.Decimal) > 0 {
+			if len(cf.Decimal) != 2 || cf.Decimal[0] < 0 || cf.Decimal[1] < 0 || cf.Decimal[0] > 255 || cf.Decimal[1] > 255 {
+				return pkg.NewSchemaException(cf.Name, "decimal must be [precision, scale]")
+			}
+			overrides = append(overrides, Precision(uint8(cf.Decimal[0]), uint8(cf.Decimal[1])))
+		}
+		if cf.Converter != "" {
+			overrides = append(overrides, Registered(Convert, cf.Converter))
+		}
+		if cf.Stringify != "" {
+			overrides = append(overrides, Registered(ToString, cf.Stringify))
+		}
+		if cf.Extension != "" {
+			overrides = append(overrides, Registered(Extension, cf.Extension))
+		}
+		field, err := NewField(t, &pkg.Nullable{
+			Allowed:     cf.Nullable.Allowed,
+			Variants:    cf.Nullable.Variants,
+			ReplaceWith: cf.Nullable.ReplaceWith,
+		}, overrides...)
+		if err != nil {
+			return pkg.NewSchemaException(cf.Name, err.Error())
+		}
+		opts = append(opts, Column(cf.Name, field, cf.Required, cf.Unique))
+		for _, a := range cf.Aliases {
+			opts = append(opts, Alias(cf.Name, a))
+		}
+		if cf.Position != nil {
+			opts = append(opts, Position(cf.Name, *cf.Position))
+		}
+		if len(cf.Span) > 0 {
+			if len(cf.Span) != 2 {
+				return pkg.NewSchemaException(cf.Name, "span must be [start, width]")
+			}
+			opts = append(opts, Span(cf.Name, cf.Span[0], cf.Span[1]))
+		}
+	}
+	for _, name := range doc.Indices {
+		opts = append(opts, Index(name))
+	}
+	for _, k := range doc.Keys {
+		policy := KeepFirst
+		if k.Policy != "" {
+			var ok bool
+			if policy, ok = keyPolicy(k.Policy); !ok {
+				return pkg.NewSchemaException(k.Name, fmt.Sprintf("unknown key policy %s", k.Policy))
+			}
+		}
+		opts = append(opts, Key(k.Name, policy, k.Columns...))
+	}
+	if doc.Positional {
+		opts = append(opts, Positional())
+	}
+	*s = *NewSchema(opts...).(*Schema)
+	return s.err
+}
+
+// Find a field type by name
+func fieldType(name string) (pkg.FieldType, bool) {
+	for t := pkg.INT8; t <= pkg.UNKNOWN; t++ {
+		if t.String() == strings.ToUpper(strings.TrimSpace(name)) {
+			return t, true
+		}
+	}
+	return pkg.UNKNOWN, false
+}
+
+// Get the field overrides of the rules of a column, see [NotBlank]
+func (rf *rulesFile) overrides() ([]FieldOverride, error) {
+	overrides := make([]FieldOverride, 0, 9)
+	if rf.NotBlank {
+		overrides = append(overrides, NotBlank())
+	}
+	if rf.MinLength != nil {
+		overrides = append(overrides, MinLength(*rf.MinLength))
+	}
+	if rf.MaxLength != nil {
+		overrides = append(overrides, MaxLength(*rf.MaxLength))
+	}
+	if rf.Pattern != "" {
+		overrides = append(overrides, Pattern(rf.Pattern))
+	}
+	if rf.Min != nil {
+		overrides = append(overrides, Min(*rf.Min))
+	}
+	if rf.Max != nil {
+		overrides = append(overrides, Max(*rf.Max))
+	}
+	for _, w := range []struct {
+		value    string
+		override func(time.Time) FieldOverride
+	}{{rf.Earliest, Earliest}, {rf.Latest, Latest}} {
+		if w.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, w.value)
+		if err != nil {
+			if t, err = time.Parse(DateLayout, w.value); err != nil {
+				return nil, errors.New(fmt.Sprintf("%s is not an RFC 3339 time or a 2006-01-02 date", w.value))
+			}
+		}
+		overrides = append(overrides, w.override(t))
+	}
+	if rf.NotFuture {
+		overrides = append(overrides, NotFuture())
+	}
+	return overrides, nil
+}
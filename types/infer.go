@@ -0,0 +1,340 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+)
+
+type (
+	// Report is the outcome of a schema inference, one entry per column in header order.
+	// Render the report as Go source with [Report.GoSource], or as a schema file with [Report.SchemaFile].
+	Report struct {
+		// Number of rows sampled
+		Rows    int
+		Columns []ColumnReport
+		schema  *Schema
+	}
+	// ColumnReport is the inferred definition of a column
+	ColumnReport struct {
+		Name string
+		T    pkg.FieldType
+		// Share of the non-null sampled values converting to T, between 0 and 1
+		Confidence float64
+		// Number of null values, and the null variants found other than an empty field
+		Nulls    int
+		Variants []string
+		// No duplicate value was found in the sample
+		Unique bool
+		// Up to 5 sampled values not converting to T
+		Rejects []string
+	}
+	// InferOpt configures a schema inference, see [Infer]
+	InferOpt func(*inference) error
+
+	inference struct {
+		confidence float64
+		nulls      []string
+	}
+	// candidate type of a column, in order of preference
+	candidate struct {
+		t      pkg.FieldType
+		accept func(string) bool
+	}
+)
+
+var (
+	numeric = regexp.MustCompile(`^\(?[-+]?\$?(\d[\d,]*(\.\d*)?|\.\d+)%?\)?$`)
+	clock   = regexp.MustCompile(`\d:\d`)
+	// null variants recognised by default
+	nullTokens = []string{"NULL", "null", "Null", "N/A", "n/a", "NA", "nil", "None", "-"}
+)
+
+// Set the minimum share of non-null values that must convert to a type for the type to be inferred,
+// the default is 1, every value must convert. Values that do not convert are listed in the report.
+func Confidence(min float64) InferOpt {
+	return func(i *inference) error {
+		if min <= 0 || min > 1 {
+			return fmt.Errorf("types/infer: confidence must be in (0, 1], got %v", min)
+		}
+		i.confidence = min
+		return nil
+	}
+}
+
+// Replace the null variants recognised in the sample, an empty field is always null.
+// The default variants are NULL, null, Null, N/A, n/a, NA, nil, None and -.
+func NullTokens(tokens ...string) InferOpt {
+	return func(i *inference) error {
+		i.nulls = tokens
+		return nil
+	}
+}
+
+// Infer a schema from a sample of rows.
+// [header] names the columns, an empty name ends the header as it does for the parser, and a repeated name is ignored.
+// Each column is given the most specific type converting its non-null values with the default converters;
+// BOOL, INT64, FLOAT64, DATE or TIMESTAMP, and STRING otherwise.
+// A column is nullable when a null value is found, and unique when no value is repeated in the sample.
+// Every inferred column is required.
+func Infer(header []string, rows [][]string, opts ...InferOpt) (*Schema, *Report, error) {
+	in := &inference{confidence: 1, nulls: nullTokens}
+	for _, opt := range opts {
+		if err := opt(in); err != nil {
+			return nil, nil, err
+		}
+	}
+	report := &Report{Rows: len(rows), Columns: make([]ColumnReport, 0, len(header))}
+	cols := make([]Opt, 0, len(header))
+	seen := make(map[string]bool)
+	for ii, name := range header {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			break
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		values := make([]string, 0, len(rows))
+		for _, row := range rows {
+			if ii < len(row) {
+				values = append(values, strings.TrimSpace(row[ii]))
+			} else {
+				values = append(values, "")
+			}
+		}
+		c := in.column(name, values)
+		field, err := NewField(c.T, &pkg.Nullable{Allowed: c.Nulls > 0, Variants: append([]string(nil), c.Variants...)})
+		if err != nil {
+			return nil, nil, err
+		}
+		report.Columns = append(report.Columns, c)
+		cols = append(cols, Column(name, field, true, c.Unique))
+	}
+	s := NewSchema(cols...).(*Schema)
+	report.schema = s
+	return s, report, s.Err()
+}
+
+// Infer the definition of a single column from its sampled values
+func (in *inference) column(name string, values []string) ColumnReport {
+	var (
+		c        = ColumnReport{Name: name, T: pkg.STRING}
+		present  = make([]string, 0, len(values))
+		variants = make(map[string]bool)
+		distinct = make(map[string]bool)
+	)
+	for _, v := range values {
+		if v == "" {
+			c.Nulls++
+			continue
+		}
+		if in.isNull(v) {
+			c.Nulls++
+			if !variants[v] {
+				variants[v] = true
+				c.Variants = append(c.Variants, v)
+			}
+			continue
+		}
+		present = append(present, v)
+		distinct[v] = true
+	}
+	if len(present) == 0 {
+		return c
+	}
+	c.Confidence = 1
+	for _, cand := range candidates(present) {
+		accepted, rejects := 0, make([]string, 0)
+		for _, v := range present {
+			if cand.accept(v) {
+				accepted++
+			} else if len(rejects) < 5 {
+				rejects = append(rejects, v)
+			}
+		}
+		if ratio := float64(accepted) / float64(len(present)); ratio >= in.confidence {
+			c.T = cand.t
+			c.Confidence = ratio
+			c.Rejects = rejects
+			break
+		}
+	}
+	// repeated values are expected of booleans, amounts and dates; unique is only inferred for identifiers
+	c.Unique = len(distinct) == len(present) && len(present) > 1 && c.Nulls == 0 &&
+		(c.T == pkg.STRING || c.T == pkg.INT64)
+	return c
+}
+
+func (in *inference) isNull(v string) bool {
+	for _, n := range in.nulls {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the candidate types of a column, in order of preference
+func candidates(values []string) []candidate {
+	var (
+		int64Converter   = IntConverter(pkg.INT64)
+		float64Converter = FloatConverter(pkg.FLOAT64)
+		words            = false
+		clocks           = false
+	)
+	for _, v := range values {
+		if v != "0" && v != "1" {
+			words = true
+		}
+		if clock.MatchString(v) {
+			clocks = true
+		}
+	}
+	date := pkg.DATE
+	if clocks {
+		date = pkg.TIMESTAMP
+	}
+	cands := make([]candidate, 0, 4)
+	if words {
+		// a column of 0 and 1 is an integer column
+		cands = append(cands, candidate{pkg.BOOL, func(v string) bool {
+			_, err := BoolConverter(&v)
+			return err == nil
+		}})
+	}
+	return append(cands,
+		candidate{pkg.INT64, func(v string) bool {
+			if !numeric.MatchString(v) || strings.Contains(v, ".") {
+				return false
+			}
+			_, err := int64Converter(&v)
+			return err == nil
+		}},
+		candidate{pkg.FLOAT64, func(v string) bool {
+			if !numeric.MatchString(v) {
+				return false
+			}
+			_, err := float64Converter(&v)
+			return err == nil
+		}},
+		candidate{date, func(v string) bool {
+			if !strings.ContainsAny(v, "0123456789") || numeric.MatchString(v) {
+				return false
+			}
+			t, err := DateTimeConverter(&v)
+			if err != nil {
+				return false
+			}
+			// a DATE column must not carry a time of day
+			return date == pkg.TIMESTAMP || t.(time.Time).Equal(t.(time.Time).Truncate(24*time.Hour))
+		}},
+	)
+}
+
+// Get the inferred schema
+func (r *Report) Schema() *Schema {
+	return r.schema
+}
+
+// Render the report as a table, one line per column
+func (r *Report) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "COLUMN\tTYPE\tCONFIDENCE\tNULLS\tUNIQUE\tREJECTED\n")
+	for _, c := range r.Columns {
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%d\t%v\t%s\n", c.Name, c.T, c.Confidence, c.Nulls, c.Unique, strings.Join(c.Rejects, ","))
+	}
+	_ = w.Flush()
+	return buf.String()
+}
+
+// Render the inferred schema as Go source, a file of package [pkgName] with a function [funcName] returning the schema.
+// Each field is annotated with the confidence of its type, review the source before use.
+func (r *Report) GoSource(w io.Writer, pkgName, funcName string) error {
+	var (
+		buf   bytes.Buffer
+		names = map[string]bool{"err": true}
+		vars  = make([]string, len(r.Columns))
+	)
+	fmt.Fprintf(&buf, "// Code generated by exttra schema inference from a sample of %d rows.\n\n", r.Rows)
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+	buf.WriteString("import (\n\t\"github.com/loanpal-engineering/exttra/pkg\"\n\t\"github.com/loanpal-engineering/exttra/types\"\n)\n\n")
+	fmt.Fprintf(&buf, "func %s() (types.Signature, error) {\n", funcName)
+	for ii, c := range r.Columns {
+		v := identifier(c.Name)
+		for n := 2; names[v]; n++ {
+			v = fmt.Sprintf("%s%d", identifier(c.Name), n)
+		}
+		names[v] = true
+		vars[ii] = v
+		fmt.Fprintf(&buf, "// %s: confidence %.2f\n", c.Name, c.Confidence)
+		nullable := fmt.Sprintf("&pkg.Nullable{Allowed: %v}", c.Nulls > 0)
+		if len(c.Variants) > 0 {
+			nullable = fmt.Sprintf("&pkg.Nullable{Allowed: true, Variants: %#v}", c.Variants)
+		}
+		fmt.Fprintf(&buf, "%s, err := types.NewField(pkg.%s, %s)\n", v, c.T, nullable)
+		buf.WriteString("if err != nil {\nreturn nil, err\n}\n")
+	}
+	buf.WriteString("return types.NewSchema(\n")
+	for ii, c := range r.Columns {
+		fmt.Fprintf(&buf, "types.Column(%q, %s, true, %v),\n", c.Name, vars[ii], c.Unique)
+	}
+	buf.WriteString("), nil\n}\n")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// Render the inferred schema as a schema file of format [f], see [WriteSchema]
+func (r *Report) SchemaFile(w io.Writer, f Format) error {
+	return WriteSchema(w, r.schema, f)
+}
+
+// Convert a column name to a lower camel case Go identifier, ex. "Loan ID" to loanId
+func identifier(name string) string {
+	var (
+		b     strings.Builder
+		upper = false
+	)
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if b.Len() == 0 {
+				if unicode.IsDigit(r) {
+					b.WriteString("col")
+					b.WriteRune(r)
+				} else {
+					b.WriteRune(unicode.ToLower(r))
+				}
+			} else if upper {
+				b.WriteRune(unicode.ToUpper(r))
+			} else {
+				b.WriteRune(unicode.ToLower(r))
+			}
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	if b.Len() == 0 {
+		return "col"
+	}
+	if token.Lookup(b.String()).IsKeyword() {
+		return b.String() + "Col"
+	}
+	return b.String()
+}
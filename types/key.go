@@ -0,0 +1,100 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+)
+
+type (
+	// KeyPolicy decides which rows sharing a key are kept, see [Key]
+	KeyPolicy int
+
+	// A key over one or more columns, the tuple of the columns' values is unique
+	KeyDefinition struct {
+		Name    string
+		Columns []string
+		Policy  KeyPolicy
+	}
+)
+
+const (
+	// Keep the first row of a key, later rows are excluded
+	KeepFirst KeyPolicy = iota
+	// Keep the last row of a key, earlier rows are excluded
+	KeepLast
+	// Exclude every row of a duplicated key
+	RejectAll
+	// Keep every row, duplicates are only reported
+	DefectOnly
+)
+
+func (k KeyPolicy) String() string {
+	if k < KeepFirst || k > DefectOnly {
+		return "UNKNOWN"
+	}
+	return [...]string{
+		"KEEP_FIRST",
+		"KEEP_LAST",
+		"REJECT_ALL",
+		"DEFECT_ONLY",
+	}[k]
+}
+
+// Key
+// Define a key [name] over [columns], the tuple of the columns' values must be unique.
+// Each duplicate is reported as a defect keyed by the values of the columns, [policy] decides the rows that are kept.
+// A unique Column is a single column key with the KeepFirst policy, only the column's cell of a duplicate row is hidden.
+// With KeepLast the defect is on the replaced row.
+//
+//	types.Key("filing", types.KeepLast, "Loan ID", "Filing Type")
+func Key(name string, policy KeyPolicy, columns ...string) Opt {
+	return func(schema *Schema) *Schema {
+		if len(columns) == 0 {
+			schema.fail(pkg.NewSchemaException(name, "key requires one or more columns"))
+			return schema
+		}
+		if policy.String() == "UNKNOWN" {
+			schema.fail(pkg.NewSchemaException(name, fmt.Sprintf("unknown key policy %d", policy)))
+			return schema
+		}
+		for _, k := range schema.keys {
+			if k.Name == name {
+				schema.fail(pkg.NewSchemaException(name, "key is already defined"))
+				return schema
+			}
+		}
+	columns:
+		for _, c := range columns {
+			for _, v := range schema.columns {
+				if v.Name == c {
+					continue columns
+				}
+			}
+			schema.fail(pkg.NewSchemaException(c, fmt.Sprintf("not found for key %s", name)))
+			return schema
+		}
+		schema.keys = append(schema.keys, &KeyDefinition{
+			Name:    name,
+			Columns: append([]string(nil), columns...),
+			Policy:  policy,
+		})
+		return schema
+	}
+}
+
+// Get the keys of this schema, unique columns are not included
+func (s *Schema) Keys() []*KeyDefinition {
+	return s.keys
+}
+
+// Find a key policy by name
+func keyPolicy(name string) (KeyPolicy, bool) {
+	for k := KeepFirst; k <= DefectOnly; k++ {
+		if k.String() == strings.ToUpper(strings.TrimSpace(name)) {
+			return k, true
+		}
+	}
+	return KeepFirst, false
+}
@@ -0,0 +1,235 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+)
+
+type (
+	// The numeric conventions of a locale, see [Locale]
+	NumberFormat struct {
+		// decimal and grouping separators, a space grouping also matches non-breaking spaces
+		Decimal  rune
+		Grouping rune
+		// a value with a % sign is divided by 100, otherwise the sign is ignored
+		Percent bool
+		// accepted currency symbols and codes, ex. $ or USD. When empty any symbol or three letter code is ignored
+		Currencies []string
+	}
+)
+
+var (
+	// 1,234.56
+	EnUS = NumberFormat{Decimal: '.', Grouping: ','}
+	// 1.234,56
+	DeDE = NumberFormat{Decimal: ',', Grouping: '.'}
+	// 1 234,56
+	FrFR = NumberFormat{Decimal: ',', Grouping: ' '}
+	// 1'234.56
+	DeCH = NumberFormat{Decimal: '.', Grouping: '\''}
+)
+
+const currencySymbols = "$€£¥"
+
+// Read the values of a numeric field following the conventions of [nf], ex. types.Locale(types.DeDE).
+// A negative is signed, in parentheses or has a trailing minus, ex. (1.234,56) and 1.234,56- are -1234.56.
+// A grouping separator is only accepted between groups of 3 digits, ex. 1.5 or 12.34.5 is a defect with DeDE.
+// Any other letter or symbol is a defect.
+//
+//	field, err := types.NewField(pkg.DECIMAL, nullable, types.Locale(types.NumberFormat{Decimal: ',', Grouping: '.', Currencies: []string{"EUR", "€"}}))
+func Locale(nf NumberFormat) FieldOverride {
+	return func(f *Field) (*Field, error) {
+		if nf.Decimal == 0 || nf.Decimal == nf.Grouping || unicode.IsDigit(nf.Decimal) || unicode.IsDigit(nf.Grouping) {
+			return f, errors.New("a locale requires a decimal separator distinct from its grouping separator")
+		}
+		switch f.T {
+		case pkg.INT8, pkg.INT16, pkg.INT, pkg.INT32, pkg.INT64, pkg.UINT8, pkg.UINT16, pkg.UINT, pkg.UINT32, pkg.UINT64,
+			pkg.FLOAT32, pkg.FLOAT, pkg.FLOAT64, pkg.DECIMAL:
+		default:
+			return f, errors.New("a locale is only set on a numeric field")
+		}
+		f.number = &nf
+		f.convert = NumberConverter(f.T, nf, f.precision, f.scale)
+		return f, nil
+	}
+}
+
+// Get the numeric conventions of a field, nil unless set with [Locale]
+func (f *Field) Locale() *NumberFormat {
+	return f.number
+}
+
+// Convert a field's value to a number of type [t] following the conventions of [nf], see [Locale].
+// [precision] and [scale] are the digits of a DECIMAL, see [Precision].
+func NumberConverter(t pkg.FieldType, nf NumberFormat, precision, scale uint8) pkg.FieldLevelConverter {
+	return func(in *string, _ ...interface{}) (interface{}, error) {
+		n, err := nf.canonical(*in)
+		if err != nil {
+			return nil, err
+		}
+		switch t {
+		case pkg.FLOAT32, pkg.FLOAT, pkg.FLOAT64:
+			bits := 64
+			if t == pkg.FLOAT32 {
+				bits = 32
+			}
+			f, err := strconv.ParseFloat(n, bits)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("types/convert: can not convert \"%s\" to %s", *in, t))
+			}
+			if t == pkg.FLOAT32 {
+				return float32(f), nil
+			}
+			return f, nil
+		case pkg.DECIMAL:
+			return DecimalConverter(precision, scale)(&n)
+		default:
+			// an integer may only have a zero fraction, ex. 12,00 or 1.250%
+			if point := strings.IndexByte(n, '.'); point >= 0 {
+				if strings.Trim(n[point+1:], "0") != "" {
+					return nil, errors.New(fmt.Sprintf("types/convert: \"%s\" is not an integer", *in))
+				}
+				n = n[:point]
+			}
+			return IntConverter(t)(&n)
+		}
+	}
+}
+
+// Rewrite a formatted number as a plain number, ex. -1234.56
+func (nf NumberFormat) canonical(in string) (string, error) {
+	var (
+		value    = strings.TrimFunc(in, unicode.IsSpace)
+		negative bool
+		percent  bool
+		fail     = errors.New(fmt.Sprintf("types/convert: can not read \"%s\" as a number", in))
+	)
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = value[1 : len(value)-1]
+	} else if strings.HasSuffix(value, "-") {
+		negative = true
+		value = value[:len(value)-1]
+	}
+	// symbols and signs are stripped in any order, ex. -$12, $-12, 12 %, USD 12
+	for stripped := true; stripped; {
+		stripped = false
+		value = strings.TrimFunc(value, unicode.IsSpace)
+		if strings.HasPrefix(value, "%") || strings.HasSuffix(value, "%") {
+			if percent {
+				return "", fail
+			}
+			percent, stripped = true, true
+			value = strings.TrimSuffix(strings.TrimPrefix(value, "%"), "%")
+			continue
+		}
+		if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+			if negative {
+				return "", fail
+			}
+			negative, stripped = value[0] == '-', true
+			value = value[1:]
+			continue
+		}
+		before := value
+		var ok bool
+		if value, ok = nf.currency(value); !ok {
+			return "", errors.New(fmt.Sprintf("types/convert: \"%s\" is not in an accepted currency", in))
+		}
+		stripped = value != before
+	}
+	// a grouping separator is only read between groups of 3 digits, ex. 1.5 is not 15 in DeDE
+	value, grouped := ungroup(value, nf.Decimal, func(r rune) bool {
+		return r == nf.Grouping || nf.Grouping == ' ' && unicode.IsSpace(r)
+	})
+	if !grouped {
+		return "", fail
+	}
+	var (
+		b      strings.Builder
+		point  = false
+		digits = 0
+	)
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+			digits++
+		case r == nf.Decimal && !point:
+			point = true
+			b.WriteByte('.')
+		default:
+			return "", fail
+		}
+	}
+	if digits == 0 {
+		return "", fail
+	}
+	n := b.String()
+	if percent && nf.Percent {
+		n = shift(n, 2)
+	}
+	if negative {
+		n = "-" + n
+	}
+	return n, nil
+}
+
+// Strip a currency symbol or code from either end of [value], false when the currency is not accepted
+func (nf NumberFormat) currency(value string) (string, bool) {
+	for _, c := range nf.Currencies {
+		if strings.HasPrefix(value, c) {
+			return strings.TrimPrefix(value, c), true
+		}
+		if strings.HasSuffix(value, c) {
+			return strings.TrimSuffix(value, c), true
+		}
+	}
+	var (
+		runes = []rune(value)
+		last  = len(runes) - 1
+		code  string
+	)
+	switch {
+	case last >= 0 && strings.ContainsRune(currencySymbols, runes[0]):
+		code = string(runes[0])
+	case last >= 0 && strings.ContainsRune(currencySymbols, runes[last]):
+		code = string(runes[last])
+	case last > 2 && isCode(runes[:3]):
+		code = string(runes[:3])
+	case last > 2 && isCode(runes[last-2:]):
+		code = string(runes[last-2:])
+	default:
+		return value, true
+	}
+	if len(nf.Currencies) > 0 {
+		return value, false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(value, code), code), true
+}
+
+func isCode(r []rune) bool {
+	for _, c := range r {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Move the point of a plain number [n] places to the left, ex. 12.5 is 0.125 for 2 places
+func shift(number string, n int) string {
+	digits, frac := number, ""
+	if point := strings.IndexByte(number, '.'); point >= 0 {
+		digits, frac = number[:point], number[point+1:]
+	}
+	if len(digits) <= n {
+		digits = strings.Repeat("0", n-len(digits)+1) + digits
+	}
+	return digits[:len(digits)-n] + "." + digits[len(digits)-n:] + frac
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+)
+
+// Converters, stringifiers and extensions referenced by name from a schema file.
+// Register functions before a schema file using them is read.
+var registry = struct {
+	sync.RWMutex
+	converters   map[string]pkg.FieldLevelConverter
+	stringifiers map[string]pkg.StringifyField
+	extensions   map[string]pkg.FieldExtension
+}{
+	converters:   make(map[string]pkg.FieldLevelConverter),
+	stringifiers: make(map[string]pkg.StringifyField),
+	extensions:   make(map[string]pkg.FieldExtension),
+}
+
+// Register a converter under [name], replacing any converter registered under the same name.
+// A CUSTOM field of a schema file references its converter by name.
+func RegisterConverter(name string, fn pkg.FieldLevelConverter) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.converters[name] = fn
+}
+
+// Register a stringify function under [name], see [RegisterConverter]
+func RegisterStringify(name string, fn pkg.StringifyField) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.stringifiers[name] = fn
+}
+
+// Register a field extension under [name], see [RegisterConverter]
+func RegisterExtension(name string, fn pkg.FieldExtension) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.extensions[name] = fn
+}
+
+// Set the converter (Convert), stringify function (ToString) or extension (Extension) of a field
+// to the function registered under [name]. The name is kept so the field can be written to a schema file.
+//
+//	types.RegisterConverter("money", money)
+//	types.RegisterStringify("money", moneyToString)
+//	field, err := types.NewField(pkg.CUSTOM, nullable, types.Registered(types.Convert, "money"), types.Registered(types.ToString, "money"))
+func Registered(m Prop, name string) FieldOverride {
+	return func(f *Field) (*Field, error) {
+		registry.RLock()
+		defer registry.RUnlock()
+		var (
+			fn interface{}
+			ok bool
+		)
+		switch m {
+		case Convert:
+			fn, ok = registry.converters[name]
+		case ToString:
+			fn, ok = registry.stringifiers[name]
+		case Extension:
+			fn, ok = registry.extensions[name]
+		default:
+			return f, errors.New("types/registry: unknown field property")
+		}
+		if !ok {
+			return f, errors.New(fmt.Sprintf("types/registry: %s is not registered", name))
+		}
+		if f.registered == nil {
+			f.registered = make(map[Prop]string)
+		}
+		f.registered[m] = name
+		switch m {
+		case Convert:
+			f.convert = fn.(pkg.FieldLevelConverter)
+		case ToString:
+			f.toString = fn.(pkg.StringifyField)
+		default:
+			f.Extension = fn.(pkg.FieldExtension)
+		}
+		return f, nil
+	}
+}
@@ -0,0 +1,270 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+)
+
+type (
+	// The business rules of a field, checked by the parser once a value is converted, see [NotBlank]
+	ruleSet struct {
+		notBlank             bool
+		minLength, maxLength *int
+		pattern              *regexp.Regexp
+		min, max             *float64
+		earliest, latest     *time.Time
+		notFuture            bool
+	}
+)
+
+// Names of the rules reported with a defect, see pkg.Defect
+const (
+	RuleNotBlank  = "not_blank"
+	RuleMinLength = "min_length"
+	RuleMaxLength = "max_length"
+	RulePattern   = "pattern"
+	RuleMin       = "min"
+	RuleMax       = "max"
+	RuleEarliest  = "earliest"
+	RuleLatest    = "latest"
+	RuleNotFuture = "not_future"
+)
+
+// Reject a null, empty or whitespace only value in a field of any type.
+// Other rules are only checked for a value that is not null.
+func NotBlank() FieldOverride {
+	return ruleOverride(func(r *ruleSet) error {
+		r.notBlank = true
+		return nil
+	})
+}
+
+// Reject a value of a STRING field shorter than [n] characters
+func MinLength(n int) FieldOverride {
+	return ruleOverride(func(r *ruleSet) error {
+		if n < 0 || r.maxLength != nil && n > *r.maxLength {
+			return errors.New(fmt.Sprintf("invalid minimum length %d", n))
+		}
+		r.minLength = &n
+		return nil
+	}, pkg.STRING)
+}
+
+// Reject a value of a STRING field longer than [n] characters
+func MaxLength(n int) FieldOverride {
+	return ruleOverride(func(r *ruleSet) error {
+		if n < 0 || r.minLength != nil && n < *r.minLength {
+			return errors.New(fmt.Sprintf("invalid maximum length %d", n))
+		}
+		r.maxLength = &n
+		return nil
+	}, pkg.STRING)
+}
+
+// Reject a value of a STRING field not matching the regular expression [expr], anchor [expr] to match the whole value
+//
+//	zip, err := types.NewField(pkg.STRING, nullable, types.Pattern(`^\d{5}(-\d{4})?$`))
+func Pattern(expr string) FieldOverride {
+	return ruleOverride(func(r *ruleSet) error {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return err
+		}
+		r.pattern = re
+		return nil
+	}, pkg.STRING)
+}
+
+// Reject a value of a numeric or DECIMAL field less than [min], a DECIMAL is compared exactly to [min] as written, ex. 0.1
+func Min(min float64) FieldOverride {
+	return ruleOverride(func(r *ruleSet) error {
+		if r.max != nil && min > *r.max {
+			return errors.New(fmt.Sprintf("minimum %v is greater than the maximum", min))
+		}
+		r.min = &min
+		return nil
+	}, numericTypes...)
+}
+
+// Reject a value of a numeric or DECIMAL field greater than [max], a DECIMAL is compared exactly, see [Min]
+func Max(max float64) FieldOverride {
+	return ruleOverride(func(r *ruleSet) error {
+		if r.min != nil && max < *r.min {
+			return errors.New(fmt.Sprintf("maximum %v is less than the minimum", max))
+		}
+		r.max = &max
+		return nil
+	}, numericTypes...)
+}
+
+// Reject a value of a DATE or TIMESTAMP field before [t], ex. types.Earliest(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+func Earliest(t time.Time) FieldOverride {
+	return ruleOverride(func(r *ruleSet) error {
+		if r.latest != nil && t.After(*r.latest) {
+			return errors.New("earliest time is after the latest time")
+		}
+		r.earliest = &t
+		return nil
+	}, pkg.DATE, pkg.TIMESTAMP)
+}
+
+// Reject a value of a DATE or TIMESTAMP field after [t]
+func Latest(t time.Time) FieldOverride {
+	return ruleOverride(func(r *ruleSet) error {
+		if r.earliest != nil && t.Before(*r.earliest) {
+			return errors.New("latest time is before the earliest time")
+		}
+		r.latest = &t
+		return nil
+	}, pkg.DATE, pkg.TIMESTAMP)
+}
+
+// Reject a value of a DATE or TIMESTAMP field after the time it is parsed, today's date is not in the future
+func NotFuture() FieldOverride {
+	return ruleOverride(func(r *ruleSet) error {
+		r.notFuture = true
+		return nil
+	}, pkg.DATE, pkg.TIMESTAMP)
+}
+
+var numericTypes = []pkg.FieldType{pkg.INT8, pkg.INT16, pkg.INT, pkg.INT32, pkg.INT64, pkg.UINT8, pkg.UINT16, pkg.UINT, pkg.UINT32,
+	pkg.UINT64, pkg.FLOAT32, pkg.FLOAT, pkg.FLOAT64, pkg.DECIMAL}
+
+// Check the raw value [in] and its converted value [v] against the rules of the field, [v] is nil for a null value.
+// The name of the first rule broken is returned with its error, see [NotBlank].
+func (f *Field) Check(in *string, v interface{}) (string, error) {
+	r := f.rules
+	if r == nil {
+		return "", nil
+	}
+	if r.notBlank && (v == nil || in != nil && strings.TrimSpace(*in) == "") {
+		return RuleNotBlank, errors.New("types/rule: a value is required")
+	}
+	if v == nil {
+		return "", nil
+	}
+	switch value := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(value)
+		if r.minLength != nil && n < *r.minLength {
+			return RuleMinLength, errors.New(fmt.Sprintf("types/rule: \"%s\" is shorter than %d characters", value, *r.minLength))
+		}
+		if r.maxLength != nil && n > *r.maxLength {
+			return RuleMaxLength, errors.New(fmt.Sprintf("types/rule: \"%s\" is longer than %d characters", value, *r.maxLength))
+		}
+		if r.pattern != nil && !r.pattern.MatchString(value) {
+			return RulePattern, errors.New(fmt.Sprintf("types/rule: \"%s\" does not match %s", value, r.pattern))
+		}
+	case time.Time:
+		if r.earliest != nil && value.Before(*r.earliest) {
+			return RuleEarliest, errors.New(fmt.Sprintf("types/rule: %s is before %s", value.Format(time.RFC3339), r.earliest.Format(time.RFC3339)))
+		}
+		if r.latest != nil && value.After(*r.latest) {
+			return RuleLatest, errors.New(fmt.Sprintf("types/rule: %s is after %s", value.Format(time.RFC3339), r.latest.Format(time.RFC3339)))
+		}
+		if r.notFuture && value.After(time.Now()) {
+			return RuleNotFuture, errors.New(fmt.Sprintf("types/rule: %s is in the future", value.Format(time.RFC3339)))
+		}
+	default:
+		if r.min == nil && r.max == nil {
+			break
+		}
+		n, ok := number(v)
+		if !ok {
+			break
+		}
+		// a decimal is compared exactly
+		d, exact := v.(pkg.Decimal)
+		if r.min != nil && (exact && cmpBound(d, *r.min) < 0 || !exact && n < *r.min) {
+			return RuleMin, errors.New(fmt.Sprintf("types/rule: %s is less than %v", *SimpleToString(v), *r.min))
+		}
+		if r.max != nil && (exact && cmpBound(d, *r.max) > 0 || !exact && n > *r.max) {
+			return RuleMax, errors.New(fmt.Sprintf("types/rule: %s is greater than %v", *SimpleToString(v), *r.max))
+		}
+	}
+	return "", nil
+}
+
+// Get a numeric value as a float64, false when [v] is not a number
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case pkg.Decimal:
+		return n.Float64(), true
+	default:
+		return 0, false
+	}
+}
+
+// Compare a decimal to bound [b], the bound is read as a decimal of its shortest representation, ex. 0.1.
+// A bound of more digits than a decimal holds is compared as a float.
+func cmpBound(d pkg.Decimal, b float64) int {
+	bound := strconv.FormatFloat(b, 'f', -1, 64)
+	scale := 0
+	if point := strings.IndexByte(bound, '.'); point >= 0 {
+		scale = len(bound) - point - 1
+	}
+	if scale <= pkg.MaxPrecision {
+		if bd, err := pkg.ParseDecimal(bound, uint8(scale)); err == nil {
+			return d.Cmp(bd)
+		}
+	}
+	switch f := d.Float64(); {
+	case f < b:
+		return -1
+	case f > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// [allowed] are the field types a rule applies to, any type when empty
+func ruleOverride(fn func(*ruleSet) error, allowed ...pkg.FieldType) FieldOverride {
+	return func(f *Field) (*Field, error) {
+		if len(allowed) > 0 {
+			found := false
+			for _, t := range allowed {
+				found = found || t == f.T
+			}
+			if !found {
+				return f, errors.New(fmt.Sprintf("the rule is not set on a %s field", f.T))
+			}
+		}
+		r := ruleSet{}
+		if f.rules != nil {
+			r = *f.rules
+		}
+		if err := fn(&r); err != nil {
+			return f, err
+		}
+		f.rules = &r
+		return f, nil
+	}
+}
@@ -2,7 +2,6 @@ package types
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/loanpal-engineering/exttra/pkg"
 )
@@ -18,12 +17,21 @@ type (
 		Name     string
 		Unique   bool
 		Required bool
+		// Offset and Width locate the column in a fixed width record, see [Span]
+		Offset int
+		Width  int
+		// Zero based position of the column in a header-less record, -1 when bound by declaration order. See [Position]
+		Position int
 	}
 	Schema struct {
-		dupes   map[string][]int
-		headers []string
-		indices []string
-		columns []*ColumnDefinition
+		dupes      map[string][]int
+		headers    []string
+		indices    []string
+		columns    []*ColumnDefinition
+		keys       []*KeyDefinition
+		positional bool
+		// the first definition error, see [Schema.Err]
+		err error
 	}
 
 	Opt func(schema *Schema) *Schema
@@ -43,6 +51,7 @@ func Column(name string, t Field, required bool, unique ...bool) Opt {
 			Field:    t,
 			Required: required,
 			Unique:   uni,
+			Position: -1,
 		})
 		return schema
 	}
@@ -62,13 +71,75 @@ func Index(name string) Opt {
 			}
 		}
 		if !found {
-			log.Fatal(fmt.Sprintf("types/schema: column %s not found", name))
+			schema.fail(pkg.NewSchemaException(name, "not found"))
+			return schema
 		}
 		schema.indices = append(schema.indices, name)
 		return schema
 	}
 }
 
+// Span
+// Locate a column in a fixed width record. [start] is the zero based
+// character offset of the column and [width] the number of characters it occupies.
+func Span(name string, start, width int) Opt {
+	return func(schema *Schema) *Schema {
+		if start < 0 || width <= 0 {
+			schema.fail(pkg.NewSchemaException(name, fmt.Sprintf("invalid span %d,%d", start, width)))
+			return schema
+		}
+		found := false
+		for _, v := range schema.columns {
+			if v.Name == name {
+				v.Offset = start
+				v.Width = width
+				found = true
+				break
+			}
+		}
+		if !found {
+			schema.fail(pkg.NewSchemaException(name, "not found"))
+		}
+		return schema
+	}
+}
+
+// Positional
+// Bind columns by position rather than by header name, for files without a header row.
+// Columns are bound in the order they are declared unless given an explicit [Position].
+// With a positional schema [parser.Validate] does not read a header and may be skipped.
+func Positional() Opt {
+	return func(schema *Schema) *Schema {
+		schema.positional = true
+		return schema
+	}
+}
+
+// Position
+// Bind a column to the zero based field [index] of a header-less record.
+// Position implies a [Positional] schema.
+func Position(name string, index int) Opt {
+	return func(schema *Schema) *Schema {
+		if index < 0 {
+			schema.fail(pkg.NewSchemaException(name, fmt.Sprintf("invalid position %d", index)))
+			return schema
+		}
+		found := false
+		for _, v := range schema.columns {
+			if v.Name == name {
+				v.Position = index
+				found = true
+				break
+			}
+		}
+		if !found {
+			schema.fail(pkg.NewSchemaException(name, "not found"))
+		}
+		schema.positional = true
+		return schema
+	}
+}
+
 // Alias
 // If a column may come in with a different Name but
 // should map to an existing column use Alias to add to the transform
@@ -84,9 +155,7 @@ func Alias(columnName string, name string) Opt {
 			}
 		}
 		if !found {
-			pkg.FatalDefect(pkg.Defect{
-				Msg: fmt.Sprintf("column [ %s ] does not exist on this schema", name),
-			})
+			schema.fail(pkg.NewSchemaException(columnName, fmt.Sprintf("not found for alias %s", name)))
 		}
 		return schema
 
@@ -96,7 +165,8 @@ func Alias(columnName string, name string) Opt {
 // Create a new Schema.
 // Build the schema through optional [opts] Column, Alias.
 // The schema signature is returned.
-// If schema fails a fatal response is thrown
+// If the definition is invalid the error is kept on the schema, see [Schema.Err],
+// and is returned by the inputs and parser using the schema.
 func NewSchema(opts ...Opt) Signature {
 	s := new(Schema)
 	s.columns = make([]*ColumnDefinition, 0, 10)
@@ -108,11 +178,41 @@ func NewSchema(opts ...Opt) Signature {
 	return s
 }
 
+// Get the first definition error of this schema, nil when the schema is valid
+func (s *Schema) Err() error {
+	return s.err
+}
+
+// Keep the first definition error
+func (s *Schema) fail(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
 // Get the column definitions of this schema.
 func (s *Schema) Cols() []*ColumnDefinition {
 	return s.columns
 }
 
+// Is this schema bound by position, see [Positional]
+func (s *Schema) IsPositional() bool {
+	return s.positional
+}
+
+// Get the zero based position of a column in a header-less record
+func (s *Schema) PositionOf(c *ColumnDefinition) int {
+	if c.Position >= 0 {
+		return c.Position
+	}
+	for i, v := range s.columns {
+		if v == c {
+			return i
+		}
+	}
+	return -1
+}
+
 // Check if a column has indexing.
 func (s *Schema) Indexed(name string) bool {
 	var (
@@ -0,0 +1,241 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/loanpal-engineering/exttra/pkg"
+)
+
+type (
+	// A column read from a struct field, see [SchemaFromStruct]
+	structColumn struct {
+		name string
+		// dotted path of the field, ex. Dates.Mailed
+		path     string
+		t        pkg.FieldType
+		nullable pkg.Nullable
+		required bool
+		unique   bool
+		index    bool
+		aliases  []string
+		// values of an ENUM column
+		enum []string
+	}
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	decimalType  = reflect.TypeOf(pkg.Decimal{})
+	categoryType = reflect.TypeOf(pkg.Category{})
+)
+
+// Create a new Schema from the fields of struct [shape], a struct value or a pointer to a struct.
+// Each exported field is a column, the column is described by the field's `exttra` tag:
+//
+//	type loan struct {
+//		Id     string     `exttra:"Loan ID,required,unique,alias=LoanNumber"`
+//		Amount float64    `exttra:",null=NULL|n/a,replace=0"`
+//		Mailed *time.Time `exttra:"Date UCC mailed,type=DATE"`
+//		Notes  string     `exttra:"-"`
+//	}
+//
+// The first tag segment is the column name, the field name when empty; "-" skips the field.
+// Options are required, unique, index, nullable, alias=name (repeatable), null=variant|variant, replace=value, type=FieldType
+// and enum=value|value for an ENUM column of a string or pkg.Category field.
+// The field type is inferred from the Go type: strings, bools, sized ints and floats, time.Time as a TIMESTAMP and pkg.Decimal as a DECIMAL.
+// int and uint are read as INT64 and UINT64. A pointer field, or a field with null variants, is nullable.
+// The fields of a nested struct are columns named by their tag or by their dotted path, ex. "Dates.Mailed".
+// The same struct reads from [input.Mem] and writes to [output.Mem] without aliases, see [StructColumns].
+// An invalid field is kept as the schema error, see [Schema.Err].
+func SchemaFromStruct(shape interface{}) Signature {
+	cols, err := structColumns(shape)
+	if err != nil {
+		s := NewSchema().(*Schema)
+		s.fail(err)
+		return s
+	}
+	opts := make([]Opt, 0, len(cols))
+	for _, c := range cols {
+		nullable := c.nullable
+		var overrides []FieldOverride
+		if len(c.enum) > 0 {
+			overrides = append(overrides, Enum(c.enum...))
+		}
+		field, err := NewField(c.t, &nullable, overrides...)
+		if err != nil {
+			s := NewSchema().(*Schema)
+			s.fail(pkg.NewSchemaException(c.name, err.Error()))
+			return s
+		}
+		opts = append(opts, Column(c.name, field, c.required, c.unique))
+		for _, a := range c.aliases {
+			opts = append(opts, Alias(c.name, a))
+		}
+		if c.path != c.name && strings.Contains(c.path, ".") {
+			// the field path reads a nested column from a struct or map in memory
+			opts = append(opts, Alias(c.name, c.path))
+		}
+		if c.index {
+			opts = append(opts, Index(c.name))
+		}
+	}
+	return NewSchema(opts...)
+}
+
+// Get the columns of struct [shape] mapped to the dotted path of their field, see [SchemaFromStruct].
+// output.Mem uses the mapping to alias the columns of a tree to the fields of its shape.
+func StructColumns(shape interface{}) (map[string]string, error) {
+	cols, err := structColumns(shape)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(cols))
+	for _, c := range cols {
+		out[c.name] = c.path
+	}
+	return out, nil
+}
+
+func structColumns(shape interface{}) ([]structColumn, error) {
+	t := reflect.TypeOf(shape)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, errors.New("types/struct: shape must be a struct")
+	}
+	return fields(t, "")
+}
+
+// Read the columns of the fields of [t], [prefix] is the path of a nested struct
+func fields(t reflect.Type, prefix string) ([]structColumn, error) {
+	cols := make([]structColumn, 0, t.NumField())
+	for ii := 0; ii < t.NumField(); ii++ {
+		f := t.Field(ii)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get("exttra")
+		if tag == "-" {
+			continue
+		}
+		c := structColumn{path: prefix + f.Name}
+		segments := strings.Split(tag, ",")
+		c.name = strings.TrimSpace(segments[0])
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+			c.nullable.Allowed = true
+			if nested(ft) {
+				return nil, pkg.NewSchemaException(c.path, "a pointer to a nested struct is not supported")
+			}
+		}
+		if nested(ft) {
+			if c.name != "" {
+				return nil, pkg.NewSchemaException(c.name, "a nested struct can not be a column")
+			}
+			nested, err := fields(ft, c.path+".")
+			if err != nil {
+				return nil, err
+			}
+			cols = append(cols, nested...)
+			continue
+		}
+		if c.name == "" {
+			c.name = c.path
+		}
+		var ok bool
+		if c.t, ok = goFieldType(ft); !ok {
+			c.t = pkg.UNKNOWN
+		}
+		for _, opt := range segments[1:] {
+			kv := strings.SplitN(strings.TrimSpace(opt), "=", 2)
+			switch kv[0] {
+			case "":
+			case "required":
+				c.required = true
+			case "unique":
+				c.unique = true
+			case "index":
+				c.index = true
+			case "nullable":
+				c.nullable.Allowed = true
+			case "alias", "null", "replace", "type", "enum":
+				if len(kv) != 2 {
+					return nil, pkg.NewSchemaException(c.name, fmt.Sprintf("tag option %s requires a value", kv[0]))
+				}
+				switch kv[0] {
+				case "alias":
+					c.aliases = append(c.aliases, kv[1])
+				case "null":
+					c.nullable.Allowed = true
+					c.nullable.Variants = append(c.nullable.Variants, strings.Split(kv[1], "|")...)
+				case "replace":
+					replace := kv[1]
+					c.nullable.ReplaceWith = &replace
+				case "type":
+					if c.t, ok = fieldType(kv[1]); !ok {
+						return nil, pkg.NewSchemaException(c.name, fmt.Sprintf("unknown type %s", kv[1]))
+					}
+				case "enum":
+					c.t = pkg.ENUM
+					c.enum = strings.Split(kv[1], "|")
+				}
+			default:
+				return nil, pkg.NewSchemaException(c.name, fmt.Sprintf("unknown tag option %s", kv[0]))
+			}
+		}
+		if c.t == pkg.UNKNOWN || c.t == pkg.CUSTOM {
+			return nil, pkg.NewSchemaException(c.name, fmt.Sprintf("field %s of type %s can not be a column", c.path, f.Type))
+		}
+		cols = append(cols, c)
+	}
+	return cols, nil
+}
+
+// Is [t] a nested struct rather than a value
+func nested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && t != decimalType && t != categoryType
+}
+
+// Get the field type of a Go type
+func goFieldType(t reflect.Type) (pkg.FieldType, bool) {
+	switch t {
+	case timeType:
+		return pkg.TIMESTAMP, true
+	case decimalType:
+		return pkg.DECIMAL, true
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return pkg.STRING, true
+	case reflect.Bool:
+		return pkg.BOOL, true
+	case reflect.Int8:
+		return pkg.INT8, true
+	case reflect.Int16:
+		return pkg.INT16, true
+	case reflect.Int32:
+		return pkg.INT32, true
+	case reflect.Int, reflect.Int64:
+		return pkg.INT64, true
+	case reflect.Uint8:
+		return pkg.UINT8, true
+	case reflect.Uint16:
+		return pkg.UINT16, true
+	case reflect.Uint32:
+		return pkg.UINT32, true
+	case reflect.Uint, reflect.Uint64:
+		return pkg.UINT64, true
+	case reflect.Float32:
+		return pkg.FLOAT32, true
+	case reflect.Float64:
+		return pkg.FLOAT64, true
+	default:
+		return pkg.UNKNOWN, false
+	}
+}
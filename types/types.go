@@ -2,7 +2,7 @@ package types
 
 import (
 	"errors"
-	"log"
+	"fmt"
 
 	"github.com/loanpal-engineering/exttra/pkg"
 )
@@ -18,6 +18,18 @@ type (
 		toString  pkg.StringifyField
 		Extension pkg.FieldExtension
 		Nil       *pkg.Nullable
+		// registry names of the functions set with [Registered]
+		registered map[Prop]string
+		// digits of a DECIMAL field, see [Precision]
+		precision, scale uint8
+		// date/time policy of a DATE or TIMESTAMP field, see [Layouts]
+		datetime *timePolicy
+		// numeric conventions of a numeric field, see [Locale]
+		number *NumberFormat
+		// values of an ENUM field, see [Enum]
+		enum *enumDef
+		// business rules checked once a value is converted, see [NotBlank]
+		rules *ruleSet
 	}
 )
 
@@ -124,6 +136,33 @@ func Override(m Prop, fn interface{}) FieldOverride {
 	}
 }
 
+// Set the digits of a DECIMAL field to [precision], [scale] of them after the point.
+// A DECIMAL field holds 18 digits, 2 after the point, by default.
+//
+//	field, err := types.NewField(pkg.DECIMAL, nullable, types.Precision(12, 4))
+func Precision(precision, scale uint8) FieldOverride {
+	return func(f *Field) (*Field, error) {
+		if f.T != pkg.DECIMAL {
+			return f, errors.New("precision is only set on a decimal field")
+		}
+		if precision == 0 || precision > pkg.MaxPrecision || scale > precision {
+			return f, errors.New(fmt.Sprintf("decimal(%d,%d) must have 1 to %d digits and no more after the point", precision, scale, pkg.MaxPrecision))
+		}
+		f.precision, f.scale = precision, scale
+		if f.number != nil {
+			f.convert = NumberConverter(f.T, *f.number, precision, scale)
+		} else {
+			f.convert = DecimalConverter(precision, scale)
+		}
+		return f, nil
+	}
+}
+
+// Get the digits of a DECIMAL field, see [Precision]
+func (f *Field) Precision() (precision, scale uint8) {
+	return f.precision, f.scale
+}
+
 // Create a new field.
 func NewField(fieldType pkg.FieldType, nullable *pkg.Nullable, opts ...FieldOverride) (field Field, err error) {
 	if nullable == nil {
@@ -145,7 +184,7 @@ func NewField(fieldType pkg.FieldType, nullable *pkg.Nullable, opts ...FieldOver
 	field.Nil = nullable
 	if field.T == pkg.CUSTOM {
 		if len(opts) < 2 {
-			log.Fatal("custom fields require opts for convert and stringify functions")
+			return Field{}, errors.New("custom fields require opts for convert and stringify functions")
 		}
 	} else {
 		field.toString = SimpleToString
@@ -179,9 +218,12 @@ func NewField(fieldType pkg.FieldType, nullable *pkg.Nullable, opts ...FieldOver
 		case pkg.FLOAT64:
 			field.convert = FloatConverter(pkg.FLOAT64)
 		case pkg.TIMESTAMP:
-			fallthrough
-		case pkg.DATE:
 			field.convert = DateTimeConverter
+		case pkg.DATE:
+			field.convert = TimeConverter(pkg.DATE, nil, nil, nil, false)
+		case pkg.DECIMAL:
+			field.precision, field.scale = pkg.MaxPrecision, 2
+			field.convert = DecimalConverter(field.precision, field.scale)
 		case pkg.STRING: // for string types no converter is necessary
 			field.convert = nil
 		default:
@@ -190,12 +232,21 @@ func NewField(fieldType pkg.FieldType, nullable *pkg.Nullable, opts ...FieldOver
 	}
 	for _, opt := range opts {
 		if fieldWithOpt, err := opt(&field); err != nil {
-			pkg.FatalDefect(pkg.Defect{
-				Msg: err.Error(),
-			})
+			return Field{}, err
 		} else {
 			field = *fieldWithOpt
 		}
 	}
+	if field.T == pkg.ENUM {
+		if field.enum == nil || field.enum.categories == nil {
+			return Field{}, errors.New("enum fields require opts for their values, see Enum")
+		}
+		if field.convert != nil {
+			return Field{}, errors.New("enum fields convert with their values, a convert function is not allowed")
+		}
+		if field.convert, err = field.enum.converter(); err != nil {
+			return Field{}, err
+		}
+	}
 	return field, nil
 }
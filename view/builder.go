@@ -1,6 +1,7 @@
 package view
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/loanpal-engineering/exttra/pkg"
@@ -13,6 +14,7 @@ type (
 		root         pkg.Composer
 		selectClause []string
 		keymap       map[uint32]interface{}
+		ctx          context.Context
 	}
 )
 
@@ -37,7 +39,10 @@ func Select(fields ...string) Opt {
 // are passed to the output to be viewed, and where [false] are hidden from output, thus not viewable.
 func Where(clause pkg.Operator) Opt {
 	return func(v *view, idx uint32) (*view, error) {
-		m, t := clause.Apply()
+		m, t, err := pkg.Evaluate(v.ctx, clause)
+		if err != nil {
+			return v, err
+		}
 		if t != pkg.BOOL {
 			return v, errors.New("where clause expressions must evaluate to boolean(s)")
 		}
@@ -55,9 +60,16 @@ func Where(clause pkg.Operator) Opt {
 // A new node is NOT returned. To access the results of a view use the same node that was provided to [From]
 // Again, to revert back to the original tree, call root.Reset()
 func NewView(opts ...Opt) error {
+	return NewViewContext(context.Background(), opts...)
+}
+
+// Create a new view, see [NewView].
+// The [Where] expression stops evaluating once [ctx] is done and the context's error is returned.
+func NewViewContext(ctx context.Context, opts ...Opt) error {
 	var err error = nil
 	i := new(view)
 	i.root = nil
+	i.ctx = ctx
 	for ii, op := range opts {
 		i, err = op(i, uint32(ii))
 		if err != nil {